@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haadi-coder/Git-Agent/internal/agent"
+	"github.com/haadi-coder/Git-Agent/internal/cache"
+	"github.com/haadi-coder/Git-Agent/internal/git"
+)
+
+const (
+	cacheMaxAge  = 14 * 24 * time.Hour
+	cacheMaxSize = 1 << 20
+)
+
+// responseCache reuses the response of an earlier run for the same staged
+// tree, HEAD and settings.
+type responseCache struct {
+	cache *cache.Cache
+	key   string
+	// refresh skips the lookup but still stores the new response.
+	refresh bool
+}
+
+// newResponseCache returns the cache of the current run. Like failing to fill
+// it, failing to compute the key is not an error of the run: write-tree fails
+// with unmerged paths, for example. The cache is nil then.
+func newResponseCache(ctx context.Context, opts *options, cfg *agent.Config, a *agent.Agent) *responseCache {
+	dir, err := git.Run(ctx, "rev-parse", "--git-path", "ga/cache")
+	if err != nil {
+		return nil
+	}
+
+	tree, err := git.Run(ctx, "write-tree")
+	if err != nil {
+		return nil
+	}
+
+	// A repository without commits has no HEAD.
+	head, _ := git.Run(ctx, "rev-parse", "--verify", "--quiet", "HEAD")
+
+	styleName := ""
+	if cfg.Style != nil {
+		styleName = cfg.Style.Name
+	}
+
+	// The ticket ID the message must reference comes from the branch name.
+	ticketKey := ""
+	if opts.TicketPattern != "" {
+		branch, _ := git.CurrentBranch(ctx)
+		ticketKey = strings.Join([]string{opts.TicketPattern, opts.TicketPlace, branch}, "\n")
+	}
+
+	return &responseCache{
+		cache: &cache.Cache{
+			Dir:     strings.TrimSpace(dir),
+			MaxAge:  cacheMaxAge,
+			MaxSize: cacheMaxSize,
+		},
+		key: cache.Key(
+			strings.TrimSpace(tree),
+			strings.TrimSpace(head),
+			string(opts.Model),
+			string(opts.ExploreModel),
+			strings.Join(cfg.Instructions, "\n"),
+			styleName,
+			ticketKey,
+			strconv.Itoa(cfg.Candidates),
+			a.PromptVersion(),
+		),
+		refresh: opts.NoCache,
+	}
+}
+
+// run returns the cached response if there is one and runs the agent
+// otherwise. The returned bool reports a cache hit. A nil cache always runs
+// the agent.
+func (c *responseCache) run(ctx context.Context, a *agent.Agent) (*agent.Response, bool, error) {
+	if c == nil {
+		resp, err := a.Run(ctx)
+		return resp, false, err
+	}
+
+	if !c.refresh {
+		data, ok, err := c.cache.Get(c.key)
+		if err != nil {
+			return nil, false, err
+		}
+
+		// A cached message that doesn't pass the current validators, e.g.
+		// after the commitlint config changed, counts as a miss.
+		if ok {
+			var resp agent.Response
+			if err := json.Unmarshal(data, &resp); err == nil && len(a.Validate(&resp)) == 0 {
+				return &resp, true, a.Restore(&resp)
+			}
+		}
+	}
+
+	resp, err := a.Run(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if resp.Type == agent.ResponseTypeResult {
+		// The cache only saves time and tokens, failing to fill it is not an
+		// error of the run.
+		if data, err := json.Marshal(resp); err == nil {
+			_ = c.cache.Put(c.key, data)
+		}
+	}
+
+	return resp, false, nil
+}
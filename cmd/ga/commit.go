@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/haadi-coder/Git-Agent/internal/agent"
+	"github.com/haadi-coder/Git-Agent/internal/git"
+	"github.com/haadi-coder/color"
+)
+
+// gpgSign is the value of -S, which may be given with or without a key ID.
+type gpgSign struct {
+	set   bool
+	keyID string
+}
+
+func (g *gpgSign) UnmarshalFlag(value string) error {
+	g.set = true
+	g.keyID = value
+
+	return nil
+}
+
+// commit creates the commit and records the result in the report.
+func commit(ctx context.Context, opts *options, message string, rep *report) error {
+	if err := performCommit(ctx, message, commitArgs(opts)); err != nil {
+		return err
+	}
+
+	rep.Outcome = outcomeCommitted
+	rep.Committed = true
+
+	sha, err := git.Run(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	rep.CommitSHA = strings.TrimSpace(sha)
+
+	return nil
+}
+
+// commitWithRetry commits and, when git or one of its hooks rejects the
+// commit, shows the output and lets the user retry with the same message or
+// ask the agent why it failed.
+func commitWithRetry(ctx context.Context, opts *options, a *agent.Agent, message string, rep *report) error {
+	for {
+		err := commit(ctx, opts, message, rep)
+
+		var commitErr *commitError
+		if err == nil || opts.NoInteractive || !errors.As(err, &commitErr) {
+			return err
+		}
+
+		fmt.Fprintln(out, color.Red("\n❌ Commit failed:"))
+		if commitErr.output != "" {
+			fmt.Fprintln(out, commitErr.output)
+		}
+
+	prompt:
+		for {
+			fmt.Fprint(out, "\n❓ Fix and re-stage, then [R]etry with the same message, [a]sk the agent or [n] abort: ")
+
+			answer, err := readLine(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to read answer: %w", err)
+			}
+
+			switch strings.ToLower(answer) {
+			case "", "r", "retry":
+				break prompt
+
+			case "a", "ask":
+				fmt.Fprintln(out, "\n🔎 Asking the agent...")
+
+				resp, err := a.ExplainCommitFailure(ctx, commitErr.output)
+				if err != nil {
+					return fmt.Errorf("failed to explain commit failure: %w", err)
+				}
+
+				fmt.Fprint(out, color.Cyan("\nSuggestion:\n"))
+				fmt.Fprintln(out, resp.Value)
+
+			case "n", "no":
+				return commitErr.err
+			}
+		}
+	}
+}
+
+// commitArgs returns the options forwarded to git commit.
+func commitArgs(opts *options) []string {
+	var args []string
+
+	switch {
+	case opts.GPGSign.set && opts.GPGSign.keyID != "":
+		args = append(args, "--gpg-sign="+opts.GPGSign.keyID)
+	case opts.GPGSign.set:
+		args = append(args, "--gpg-sign")
+	}
+
+	if opts.Author != "" {
+		args = append(args, "--author="+opts.Author)
+	}
+	if opts.Date != "" {
+		args = append(args, "--date="+opts.Date)
+	}
+	if opts.NoVerify {
+		args = append(args, "--no-verify")
+	}
+	if opts.AllowEmpty {
+		args = append(args, "--allow-empty")
+	}
+	if opts.Amend {
+		args = append(args, "--amend")
+	}
+	if opts.Cleanup != "" {
+		args = append(args, "--cleanup="+opts.Cleanup)
+	}
+
+	return args
+}
+
+// commitError is a failed git commit together with the output of git and its
+// hooks.
+type commitError struct {
+	err    error
+	output string
+}
+
+func (e *commitError) Error() string {
+	if e.output == "" {
+		return e.err.Error()
+	}
+
+	return fmt.Sprintf("%s\n%s", e.err, e.output)
+}
+
+func (e *commitError) Unwrap() error {
+	return e.err
+}
+
+// performCommit commits with the message read from stdin, so that it is kept
+// exactly as generated.
+func performCommit(ctx context.Context, message string, args []string) error {
+	args = append([]string{"commit", "-F", "-"}, args...)
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stdin = strings.NewReader(message)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return &commitError{err: fmt.Errorf("git commit: %w", err), output: strings.TrimSpace(string(output))}
+	}
+
+	return nil
+}
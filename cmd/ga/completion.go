@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/haadi-coder/Git-Agent/internal/llm"
+	"github.com/jessevdk/go-flags"
+)
+
+const (
+	modelsCacheTTL     = 24 * time.Hour
+	modelsFetchTimeout = 3 * time.Second
+)
+
+// modelName is the value of --model, completed from the cached model list.
+// It may list several models separated by commas.
+type modelName string
+
+// Models returns the listed models in order.
+func (m modelName) Models() []string {
+	var models []string
+	for _, model := range strings.Split(string(m), ",") {
+		if model = strings.TrimSpace(model); model != "" {
+			models = append(models, model)
+		}
+	}
+
+	return models
+}
+
+func (m *modelName) Complete(match string) []flags.Completion {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), modelsFetchTimeout)
+	defer cancel()
+
+	models, err := llm.CachedModels(ctx, filepath.Join(dir, "ga", "models.json"), modelsCacheTTL)
+	if err != nil {
+		return nil
+	}
+
+	// Only the last model of a list is completed.
+	listed := ""
+	if i := strings.LastIndex(match, ","); i >= 0 {
+		listed, match = match[:i+1], match[i+1:]
+	}
+
+	var completions []flags.Completion
+	for _, model := range models {
+		if strings.HasPrefix(model, match) {
+			completions = append(completions, flags.Completion{Item: listed + model})
+		}
+	}
+
+	return completions
+}
+
+// shellName is the shell argument of the completion command.
+type shellName string
+
+func (s *shellName) Complete(match string) []flags.Completion {
+	var completions []flags.Completion
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		if strings.HasPrefix(shell, match) {
+			completions = append(completions, flags.Completion{Item: shell})
+		}
+	}
+
+	return completions
+}
+
+// completeChoices completes the value of an option declared with choices,
+// which go-flags leaves to the option type.
+func completeChoices(parser *flags.Parser, args []string) []flags.Completion {
+	if len(args) == 0 {
+		return nil
+	}
+
+	var (
+		opt    *flags.Option
+		prefix string
+		match  = args[len(args)-1]
+	)
+
+	if name, value, ok := strings.Cut(match, "="); ok && strings.HasPrefix(name, "--") {
+		opt = parser.FindOptionByLongName(strings.TrimPrefix(name, "--"))
+		prefix, match = name+"=", value
+	} else if len(args) > 1 {
+		switch prev := args[len(args)-2]; {
+		case strings.HasPrefix(prev, "--"):
+			opt = parser.FindOptionByLongName(strings.TrimPrefix(prev, "--"))
+		case strings.HasPrefix(prev, "-") && len(prev) == 2:
+			opt = parser.FindOptionByShortName(rune(prev[1]))
+		}
+	}
+
+	if opt == nil {
+		return nil
+	}
+
+	var completions []flags.Completion
+	for _, choice := range opt.Choices {
+		if strings.HasPrefix(choice, match) {
+			completions = append(completions, flags.Completion{Item: prefix + choice})
+		}
+	}
+
+	return completions
+}
+
+func printCompletions(parser *flags.Parser) func(items []flags.Completion) {
+	return func(items []flags.Completion) {
+		if len(items) == 0 {
+			items = completeChoices(parser, os.Args[1:])
+		}
+
+		for _, item := range items {
+			fmt.Println(item.Item)
+		}
+
+		os.Exit(0)
+	}
+}
+
+func completionScript(shell string) (string, error) {
+	switch shell {
+	case "bash":
+		return bashCompletion, nil
+	case "zsh":
+		return zshCompletion, nil
+	case "fish":
+		return fishCompletion, nil
+	}
+
+	return "", fmt.Errorf("unsupported shell %q, must be one of: bash, zsh, fish", shell)
+}
+
+const bashCompletion = `# bash completion for ga
+_ga() {
+    local IFS=$'\n'
+    COMPREPLY=($(GO_FLAGS_COMPLETION=1 "${COMP_WORDS[0]}" "${COMP_WORDS[@]:1:$COMP_CWORD}" 2>/dev/null))
+    return 0
+}
+complete -o default -F _ga ga
+`
+
+const zshCompletion = `#compdef ga
+_ga() {
+    local -a completions
+    completions=("${(@f)$(GO_FLAGS_COMPLETION=1 ${words[1]} "${(@)words[2,$CURRENT]}" 2>/dev/null)}")
+    compadd -- $completions
+}
+
+if [ "$funcstack[1]" = "_ga" ]; then
+    _ga "$@"
+else
+    compdef _ga ga
+fi
+`
+
+const fishCompletion = `# fish completion for ga
+function __ga_complete
+    set -l args (commandline -opc) (commandline -ct)
+    GO_FLAGS_COMPLETION=1 $args[1] $args[2..-1] 2>/dev/null
+end
+complete -c ga -f -a '(__ga_complete)'
+`
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/haadi-coder/Git-Agent/internal/agent"
+	"github.com/haadi-coder/color"
+)
+
+// lineHooks prints the agent activity as plain lines of text.
+func lineHooks(opts *options) *agent.Hooks {
+	hooks := &agent.Hooks{}
+
+	// streaming tracks whether streamed text is being printed on the current
+	// line, so it can be terminated before other output.
+	streaming := false
+	endStream := func() {
+		if streaming {
+			fmt.Fprint(out, "\n")
+			streaming = false
+		}
+	}
+
+	agent.Subscribe(hooks, func(ctx context.Context, e *agent.ContentDeltaEvent) {
+		if !streaming {
+			fmt.Fprint(out, "\n"+color.Yellow("Agent:")+" ")
+			streaming = true
+		}
+
+		fmt.Fprint(out, e.Delta)
+	})
+
+	agent.Subscribe(hooks, func(ctx context.Context, e *agent.MessageDeltaEvent) {
+		if !streaming {
+			fmt.Fprint(out, "\n✍️  ")
+			streaming = true
+		}
+
+		fmt.Fprint(out, color.Black(strings.ReplaceAll(e.Delta, "\n", "\n   ")))
+	})
+
+	agent.Subscribe(hooks, func(ctx context.Context, e *agent.LLMResponseEvent) {
+		message := e.Response.Choices[0].Message
+
+		if len(message.ToolCalls) > 0 && message.Content != "" {
+			if opts.NoStream {
+				fmt.Fprint(out, "\n")
+				fmt.Fprintln(out, color.Yellow("Agent:"), message.Content)
+			} else {
+				endStream()
+			}
+			fmt.Fprint(out, "\n")
+		}
+
+		if !opts.Verbose {
+			return
+		}
+
+		endStream()
+
+		details := []string{fmt.Sprintf("step %d", e.Step)}
+		if e.Response.Model != "" {
+			details = append(details, e.Response.Model)
+		}
+		if e.Explorer {
+			details = append(details, "exploring")
+		}
+		details = append(details, fmt.Sprintf("%d tokens", e.Response.Usage.CompletionTokens), e.Duration.Round(time.Millisecond).String())
+
+		fmt.Fprintln(out, color.Black("("+strings.Join(details, ", ")+")"))
+	})
+
+	agent.Subscribe(hooks, func(ctx context.Context, e *agent.ToolStartEvent) {
+		endStream()
+
+		fmt.Fprint(out, "\n")
+		fmt.Fprintf(out, color.Blue("Tool: ")+"%s(%s)", e.ToolCall.Function.Name, e.ToolCall.Function.Arguments)
+		fmt.Fprint(out, "\n")
+	})
+
+	agent.Subscribe(hooks, func(ctx context.Context, e *agent.ToolEndEvent) {
+		if !opts.Verbose {
+			return
+		}
+
+		if e.Result.Err != nil {
+			fmt.Fprintf(out, color.Black("  %s failed in %s: %v\n"), e.ToolCall.Function.Name, e.Result.Duration.Round(time.Millisecond), e.Result.Err)
+			return
+		}
+
+		fmt.Fprintf(out, color.Black("  %s done in %s\n"), e.ToolCall.Function.Name, e.Result.Duration.Round(time.Millisecond))
+	})
+
+	agent.Subscribe(hooks, func(ctx context.Context, e *agent.ValidationFailedEvent) {
+		endStream()
+
+		if !opts.Verbose {
+			return
+		}
+
+		fmt.Fprintln(out, color.Yellow("\nMessage rejected, asking agent to fix:"))
+		for _, v := range e.Violations {
+			fmt.Fprintln(out, color.Black(" - "+v))
+		}
+	})
+
+	agent.Subscribe(hooks, func(ctx context.Context, e *agent.RetryEvent) {
+		if !opts.Verbose {
+			return
+		}
+
+		fmt.Fprintf(out, color.Yellow("\n↻ Retry %d/%d in %s: ")+"%v\n", e.Attempt, opts.Retries, e.Delay.Round(time.Millisecond), e.Err)
+	})
+
+	agent.Subscribe(hooks, func(ctx context.Context, e *agent.FallbackEvent) {
+		if !opts.Verbose {
+			return
+		}
+
+		fmt.Fprintf(out, color.Yellow("\n⤵ %s failed, trying the next model: ")+"%v\n", e.Model, e.Err)
+	})
+
+	agent.Subscribe(hooks, func(ctx context.Context, e *agent.FinalEvent) {
+		if !opts.Verbose {
+			return
+		}
+
+		endStream()
+
+		if e.Model != "" {
+			fmt.Fprintf(out, color.Black("🤖 Written by %s, %d tokens used in total\n"), e.Model, e.Usage.TotalTokens)
+			return
+		}
+
+		fmt.Fprintf(out, color.Black("🤖 %d tokens used in total\n"), e.Usage.TotalTokens)
+	})
+
+	return hooks
+}
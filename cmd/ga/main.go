@@ -1,34 +1,90 @@
 package main
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/haadi-coder/Git-Agent/internal/agent"
+	"github.com/haadi-coder/Git-Agent/internal/commitlint"
+	"github.com/haadi-coder/Git-Agent/internal/git"
 	"github.com/haadi-coder/Git-Agent/internal/llm"
+	"github.com/haadi-coder/Git-Agent/internal/style"
+	"github.com/haadi-coder/Git-Agent/internal/ticket"
+	"github.com/haadi-coder/Git-Agent/internal/trailer"
+	"github.com/haadi-coder/Git-Agent/internal/tui"
 	"github.com/haadi-coder/color"
 	"github.com/jessevdk/go-flags"
-	"github.com/openai/openai-go"
+	"golang.org/x/term"
 )
 
 const revision = "unknown"
 
+const outputJSON = "json"
+
+const amendInstruction = "The commit amends the last commit: describe the changes of HEAD (see `git show HEAD`) together with the staged changes."
+
+// out receives all human readable output. It is stdout unless stdout is
+// reserved for machine readable output.
+var out io.Writer = os.Stdout
+
 type options struct {
 	APIKey        string        `short:"k" long:"api-key" description:"API key for LLM provider" env:"GA_API_KEY" `
-	Model         string        `short:"m" long:"model" description:"Model to use" env:"GA_MODEL" default:"openai/gpt-4o"`
-	MaxTokens     int64         `short:"t" long:"max-tokens" description:"Maximum tokens per session" env:"GA_MAX_TOKENS" default:"8192"`
+	Model         modelName     `short:"m" long:"model" description:"Model to use, or a comma separated list of models tried in order when one fails" env:"GA_MODEL" default:"openai/gpt-4o"`
+	ExploreModel  modelName     `long:"explore-model" description:"Cheaper model for exploring the repository with tools, --model then only writes the final message" env:"GA_EXPLORE_MODEL"`
+	MaxTokens     int64         `short:"t" long:"max-tokens" description:"Token budget of the whole session, prompt and completion tokens of all model calls (0 for no limit)" env:"GA_MAX_TOKENS" default:"100000"`
+	MaxSteps      int           `long:"max-steps" description:"Maximum number of model calls in the session (0 for no limit)" env:"GA_MAX_STEPS" default:"20"`
+	MaxCompletion int64         `long:"max-completion-tokens" description:"Completion tokens of a single model call, including the reasoning of reasoning models" env:"GA_MAX_COMPLETION_TOKENS" default:"4096"`
 	Timeout       time.Duration `long:"timeout" description:"API request timeout" env:"GA_TIMEOUT" default:"30s"`
+	Retries       int           `long:"retries" description:"Retries of a model call failing with a rate limit, server or network error" env:"GA_RETRIES" default:"3"`
+	RetryDelay    time.Duration `long:"retry-delay" description:"Delay before the first retry, doubled for every further one" env:"GA_RETRY_DELAY" default:"1s"`
+	RetryMaxDelay time.Duration `long:"retry-max-delay" description:"Longest delay between retries, also the longest Retry-After waited for" env:"GA_RETRY_MAX_DELAY" default:"30s"`
 	Instructions  []string      `short:"i" long:"instruction" description:"Additional instruction for the agent (can be used multiple times)" env:"GA_INSTRUCTIONS" env-delim:"\n"`
 	Verbose       bool          `short:"v" long:"verbose" description:"Show detailed agent actions" env:"GA_VERBOSE"`
 	NoInteractive bool          `short:"y" long:"non-interactive" description:"Commit without confirmation prompt" env:"GA_NO_INTERACTIVE"`
+	Candidates    int           `short:"n" long:"candidates" description:"Number of alternative messages to choose from" env:"GA_CANDIDATES" default:"1"`
+	Style         string        `long:"style" description:"Commit style preset; 'auto' picks one from the commit history (inferred by the agent if unset)" env:"GA_STYLE" choice:"conventional" choice:"gitmoji" choice:"angular" choice:"kernel" choice:"plain" choice:"auto"`
+	TicketPattern string        `long:"ticket-pattern" description:"Regular expression extracting a ticket ID from the branch name (e.g. '[A-Z]+-[0-9]+')" env:"GA_TICKET_PATTERN"`
+	TicketPlace   string        `long:"ticket-placement" description:"Where the ticket ID goes in the message" env:"GA_TICKET_PLACEMENT" choice:"prefix" choice:"scope" choice:"trailer" default:"trailer"`
+	SignOff       bool          `short:"s" long:"signoff" description:"Add a Signed-off-by trailer using user.name and user.email" env:"GA_SIGNOFF"`
+	Pairs         []string      `long:"pair" description:"Co-authors to credit, as aliases from the pairs file or 'Name <email>' (comma separated)" env:"GA_PAIR" env-delim:","`
+	PairsFile     string        `long:"pairs-file" description:"File mapping pair aliases to 'Name <email>' (default: .pairs in the repository root)" env:"GA_PAIRS_FILE"`
+	Trailers      []string      `long:"trailer" description:"Static 'Key: value' trailer to add to the message (can be used multiple times)" env:"GA_TRAILERS" env-delim:"\n"`
+	GPGSign       gpgSign       `short:"S" long:"gpg-sign" description:"GPG-sign the commit, optionally with the given key ID" env:"GA_GPG_SIGN" optional:"yes" optional-value:"" value-name:"KEYID"`
+	Author        string        `long:"author" description:"Override the commit author ('Name <email>')"`
+	Date          string        `long:"date" description:"Override the author date"`
+	NoVerify      bool          `long:"no-verify" description:"Bypass the pre-commit and commit-msg hooks" env:"GA_NO_VERIFY"`
+	AllowEmpty    bool          `long:"allow-empty" description:"Allow a commit without staged changes"`
+	Amend         bool          `long:"amend" description:"Replace the last commit, describing its changes together with the staged ones"`
+	Cleanup       string        `long:"cleanup" description:"How git cleans up the message" choice:"strip" choice:"whitespace" choice:"verbatim" choice:"scissors" choice:"default"`
+	Fast          bool          `long:"fast" description:"Send status, staged diff and recent history with the first request (done automatically for small diffs)" env:"GA_FAST"`
+	NoCache       bool          `long:"no-cache" description:"Generate a new message even if one is cached for the staged changes" env:"GA_NO_CACHE"`
+	Record        string        `long:"record" description:"Record every model request and response to this cassette file" env:"GA_RECORD"`
+	Replay        string        `long:"replay" description:"Serve model responses from this cassette file instead of calling the API" env:"GA_REPLAY"`
+	ReplayMatch   string        `long:"replay-match" description:"How replayed requests are matched to the cassette" env:"GA_REPLAY_MATCH" choice:"messages" choice:"exact" choice:"sequence" default:"messages"`
+	NoStream      bool          `long:"no-stream" description:"Wait for complete model responses instead of streaming them" env:"GA_NO_STREAM"`
+	DryRun        bool          `long:"dry-run" description:"Generate and show the message without prompting or committing" env:"GA_DRY_RUN"`
+	MessageFile   string        `long:"message-file" description:"Write the final message to this file (e.g. for 'git commit -F')" env:"GA_MESSAGE_FILE"`
+	Print         bool          `long:"print" description:"Write only the raw message on stdout and everything else on stderr" env:"GA_PRINT"`
+	Output        string        `long:"output" description:"Output format; 'json' prints a single JSON document on stdout and progress on stderr" env:"GA_OUTPUT" choice:"text" choice:"json" default:"text"`
+	TUI           bool          `long:"tui" description:"Use a full-screen terminal UI (falls back to line output when stdout is not a terminal)" env:"GA_TUI"`
 	Version       bool          `long:"version" description:"Show version information"`
+
+	Commit     struct{}          `command:"commit" description:"Generate a commit message for the staged changes and commit"`
+	Completion completionCommand `command:"completion" description:"Print a shell completion script"`
+}
+
+type completionCommand struct {
+	Args struct {
+		Shell shellName `positional-arg-name:"shell" description:"bash, zsh or fish"`
+	} `positional-args:"yes" required:"yes"`
 }
 
 func main() {
@@ -38,23 +94,66 @@ func main() {
 		os.Exit(1)
 	}
 
+	if opts.Version {
+		fmt.Printf("Git Agent %s\n", revision)
+		os.Exit(0)
+	}
+
+	if len(args) > 0 && args[0] == "completion" {
+		script, err := completionScript(string(opts.Completion.Args.Shell))
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		fmt.Print(script)
+		os.Exit(0)
+	}
+
 	if len(args) == 0 || args[0] != "commit" {
 		fmt.Println("Usage: ga commit [options]\nUse 'ga commit --help' for more information.")
 		os.Exit(1)
 	}
 
-	if opts.Version {
-		fmt.Printf("Git Agent %s\n", revision)
-		os.Exit(0)
+	if opts.Output == outputJSON && opts.Print {
+		fmt.Fprintln(os.Stderr, color.Red("Error: --print cannot be combined with --output json"))
+		os.Exit(exitCodes[outcomeError])
+	}
+
+	if len(opts.Model.Models()) == 0 {
+		fmt.Fprintln(os.Stderr, color.Red("Error: --model must name at least one model"))
+		os.Exit(exitCodes[outcomeError])
+	}
+
+	if opts.ExploreModel != "" && len(opts.ExploreModel.Models()) == 0 {
+		fmt.Fprintln(os.Stderr, color.Red("Error: --explore-model must name at least one model"))
+		os.Exit(exitCodes[outcomeError])
+	}
+
+	if opts.Output == outputJSON || opts.Print {
+		useStderr()
 	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
-	if err := run(ctx, opts); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	rep := &report{Model: opts.Model.Models()[0]}
+
+	err = run(ctx, opts, rep)
+	if err != nil && (rep.Outcome == "" || rep.Outcome == outcomeCommitted) {
+		rep.Outcome = outcomeError
 	}
+
+	if opts.Output == outputJSON {
+		if err := rep.write(os.Stdout, err); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	} else if err != nil {
+		fmt.Fprintln(out, err)
+	}
+
+	cancel()
+	os.Exit(rep.exitCode())
 }
 
 func parseOpts() (*options, []string, error) {
@@ -62,6 +161,8 @@ func parseOpts() (*options, []string, error) {
 
 	parser := flags.NewParser(&opts, flags.Default)
 	parser.Usage = "AI-powered commit message generator\n\nExample:\n  ga commit [options]"
+	parser.SubcommandsOptional = true
+	parser.CompletionHandler = printCompletions(parser)
 
 	args, err := parser.Parse()
 	if err != nil {
@@ -72,134 +173,470 @@ func parseOpts() (*options, []string, error) {
 		}
 	}
 
+	if parser.Active != nil {
+		args = append([]string{parser.Active.Name}, args...)
+	}
+
 	return &opts, args, nil
 }
 
-func run(ctx context.Context, opts *options) error {
-	llm := llm.NewOpenRouter(&llm.OpenRouterConfig{
-		APIKey:    opts.APIKey,
-		Model:     opts.Model,
-		MaxTokens: opts.MaxTokens,
-		Timeout:   opts.Timeout,
+func run(ctx context.Context, opts *options, rep *report) error {
+	if opts.Candidates < 1 {
+		return errors.New(color.Red("Error: --candidates must be at least 1\n"))
+	}
+
+	staged, err := git.HasStagedChanges(ctx)
+	if err != nil {
+		return fmt.Errorf(color.Red("Error: %w\n"), err)
+	}
+
+	if !staged && !opts.AllowEmpty && !opts.Amend {
+		rep.Outcome = outcomeNothingStaged
+		fmt.Fprintln(out, color.Yellow("Nothing staged to commit, use 'git add' to stage changes"))
+		return nil
+	}
+
+	var ui *tui.UI
+	if opts.TUI && !opts.DryRun && out == os.Stdout && term.IsTerminal(int(os.Stdout.Fd())) {
+		ui = tui.New()
+	}
+
+	var hooks *agent.Hooks
+	if ui != nil {
+		hooks = tuiHooks(ui, opts)
+	} else {
+		hooks = lineHooks(opts)
+	}
+
+	agent.Subscribe(hooks, func(ctx context.Context, e *agent.ToolStartEvent) {
+		rep.ToolCalls = append(rep.ToolCalls, toolCallReport{
+			Name:      e.ToolCall.Function.Name,
+			Arguments: e.ToolCall.Function.Arguments,
+		})
 	})
 
-	hooks := &agent.Hooks{}
+	cfg := &agent.Config{
+		Instructions:        opts.Instructions,
+		Candidates:          opts.Candidates,
+		Stream:              !opts.NoStream,
+		MaxTokens:           opts.MaxTokens,
+		MaxSteps:            opts.MaxSteps,
+		MaxCompletionTokens: opts.MaxCompletion,
+	}
+
+	if opts.Amend {
+		cfg.Instructions = append(cfg.Instructions, amendInstruction)
+	}
+
+	cfg.RepoContext, err = prefetch(ctx, opts.Fast)
+	if err != nil {
+		return fmt.Errorf(color.Red("Error: %w\n"), err)
+	}
 
-	hooks.AddOnIntermidiateStep(func(ctx context.Context, response *openai.ChatCompletion) {
-		message := response.Choices[0].Message
-		if message.Content == "" {
-			return
+	if opts.Style != "" {
+		preset, err := resolveStyle(ctx, opts.Style)
+		if err != nil {
+			return fmt.Errorf(color.Red("Error: %w\n"), err)
 		}
 
-		fmt.Print("\n")
-		fmt.Println(color.Yellow("Agent:"), message.Content)
-		fmt.Print("\n")
-	})
+		cfg.Style = preset
+	}
 
-	hooks.AddOnAfterIntermidiateStep(func(ctx context.Context, response *openai.ChatCompletion) {
-		if !opts.Verbose {
-			return
+	if opts.TicketPattern != "" {
+		t, err := branchTicket(ctx, opts.TicketPattern, opts.TicketPlace)
+		if err != nil {
+			return fmt.Errorf(color.Red("Error: %w\n"), err)
 		}
 
-		timeSpent := int(time.Now().Unix() - response.Created)
-		usedTokens := int(response.Usage.CompletionTokens)
+		if t != nil {
+			if cfg.Style != nil && t.Placement == ticket.PlacementPrefix {
+				// The preset builds the subject line from the message
+				// fields, so the prefix is added when it is rendered.
+				cfg.Style = cfg.Style.WithPrefix(t.ID)
+			} else {
+				cfg.Instructions = append(cfg.Instructions, t.Instruction())
+			}
 
-		fmt.Printf(color.Black("(%d tokens, %ds)\n"), usedTokens, timeSpent)
-	})
+			cfg.Validators = append(cfg.Validators, t.Validate)
+		}
+	}
 
-	hooks.AddBeforeCallTool(func(ctx context.Context, toolCall *openai.ChatCompletionMessageToolCall) {
-		name := toolCall.Function.Name
-		args := toolCall.Function.Arguments
+	root, err := git.TopLevel(ctx)
+	if err != nil {
+		return fmt.Errorf(color.Red("Error: %w\n"), err)
+	}
 
-		fmt.Print("\n")
-		fmt.Printf(color.Blue("Tool: ")+"%s(%s)", name, args)
-		fmt.Print("\n")
-	})
+	lintCfg, err := commitlint.Load(root)
+	if err != nil {
+		return fmt.Errorf(color.Red("Error: %w\n"), err)
+	}
+
+	if lintCfg != nil {
+		cfg.Instructions = append(cfg.Instructions, lintCfg.Instructions()...)
+		cfg.Validators = append(cfg.Validators, lintCfg.Validate)
+	}
+
+	trailers, err := buildTrailers(ctx, opts)
+	if err != nil {
+		return fmt.Errorf(color.Red("Error: %w\n"), err)
+	}
+
+	provider, explorer, err := newProviders(opts, hooks)
+	if err != nil {
+		return fmt.Errorf(color.Red("Error: %w\n"), err)
+	}
+	cfg.Explorer = explorer
 
-	a, err := agent.NewAgent(llm, opts.Instructions, hooks)
+	a, err := agent.NewAgent(provider, cfg, hooks)
 	if err != nil {
 		return fmt.Errorf(color.Red("Error: %w\n"), err)
 	}
+	defer func() {
+		rep.Usage = a.Usage()
+		if model := a.Model(); model != "" {
+			rep.Model = model
+		}
+	}()
+
+	// Recorded and replayed sessions must reach the provider.
+	var rc *responseCache
+	if opts.Record == "" && opts.Replay == "" {
+		rc = newResponseCache(ctx, opts, cfg, a)
+	}
 
-	if opts.Verbose {
-		fmt.Println(color.Cyan("=== Git Agent Session Started ==="))
-		fmt.Printf(color.Black("⌛ Start Time: ")+"%s\n", time.Now().Format(time.TimeOnly))
-		fmt.Printf(color.Black("🚩 Max Tokens: ")+"%d\n", opts.MaxTokens)
-		fmt.Printf(color.Black("🤖 Model: ")+"%s", opts.Model)
+	if opts.Verbose && ui == nil {
+		fmt.Fprintln(out, color.Cyan("=== Git Agent Session Started ==="))
+		fmt.Fprintf(out, color.Black("⌛ Start Time: ")+"%s\n", time.Now().Format(time.TimeOnly))
+		fmt.Fprintf(out, color.Black("🚩 Max Tokens: ")+"%d\n", opts.MaxTokens)
+		fmt.Fprintf(out, color.Black("👣 Max Steps: ")+"%d\n", opts.MaxSteps)
+		fmt.Fprintf(out, color.Black("🤖 Model: ")+"%s\n", opts.Model)
+		if opts.ExploreModel != "" {
+			fmt.Fprintf(out, color.Black("🔭 Explore Model: ")+"%s\n", opts.ExploreModel)
+		}
 		if len(opts.Instructions) > 0 {
-			fmt.Println(color.Black("📝 Instructions: "), strings.Join(opts.Instructions, ", "))
+			fmt.Fprintln(out, color.Black("📝 Instructions: "), strings.Join(opts.Instructions, ", "))
+		}
+		if cfg.Style != nil {
+			fmt.Fprintf(out, color.Black("🎨 Style: ")+"%s\n", cfg.Style.Name)
+		}
+		if lintCfg != nil {
+			fmt.Fprintf(out, color.Black("📏 Commitlint: ")+"%s\n", lintCfg.Path)
 		}
-		fmt.Print("\n\n")
+		if cfg.RepoContext != nil {
+			fmt.Fprintln(out, color.Black("⚡ Fast: ")+"repository context sent up front")
+		}
+		fmt.Fprint(out, "\n")
+	}
+
+	if ui != nil {
+		return runTUI(ctx, ui, a, rc, trailers, opts, rep)
 	}
 
-	fmt.Println("🔎 Analyzing changes...")
+	fmt.Fprintln(out, "🔎 Analyzing changes...")
 
-	resp, err := a.Run(ctx)
+	resp, cached, err := rc.run(ctx, a)
 	if err != nil {
-		return fmt.Errorf(color.Red("Error: %w\n"), err)
+		rep.Outcome = outcomeAgentError
+		return agentError(err)
 	}
 
-	switch resp.Type {
-	case agent.ResponseTypeError:
-		return fmt.Errorf(color.Red("llm error: %s"), resp.Value)
+	if cached {
+		rep.Cached = true
+		fmt.Fprintln(out, color.Black("⚡ Using the cached message for these changes, run with --no-cache for a new one"))
+	}
 
-	case agent.ResponseTypeSuggestion:
-		fmt.Print(color.Cyan("\nSuggestion:\n"))
-		fmt.Println(resp.Value)
+	for {
+		rep.Type = resp.Type
+		rep.Value = resp.Value
+		rep.Commit = resp.Commit
 
-	case agent.ResponseTypeResult:
-		fmt.Println(color.Cyan("\n📜 Generated commit message:"))
-		fmt.Println(resp.Value)
+		switch resp.Type {
+		case agent.ResponseTypeError:
+			rep.Outcome = outcomeAgentError
+			return fmt.Errorf(color.Red("llm error: %s"), resp.Value)
 
-		if !opts.NoInteractive {
-			fmt.Print("\n❓ Commit with this message? [Y/n]: ")
+		case agent.ResponseTypeBudgetExhausted:
+			rep.Outcome = outcomeAgentError
+			return fmt.Errorf(color.Red("budget exhausted: %s, raise --max-tokens or --max-steps"), resp.Value)
 
-			ok, err := confirm(ctx)
-			if err != nil {
-				return fmt.Errorf("\nfailed to confirm: %w", err)
+		case agent.ResponseTypeSuggestion:
+			rep.Outcome = outcomeSuggestion
+
+			fmt.Fprint(out, color.Cyan("\nSuggestion:\n"))
+			fmt.Fprintln(out, resp.Value)
+
+		case agent.ResponseTypeResult:
+			candidates := resp.Candidates
+			if len(candidates) == 0 {
+				candidates = []agent.Candidate{{Value: resp.Value, Commit: resp.Commit}}
 			}
 
-			if !ok {
-				fmt.Println(color.Red("❌ Message not committed"))
-				return nil
+			for i := range candidates {
+				candidates[i].Value, err = trailer.Apply(ctx, candidates[i].Value, trailers)
+				if err != nil {
+					return fmt.Errorf(color.Red("Error: %w\n"), err)
+				}
 			}
+
+			printCandidates(candidates)
+			message := candidates[0].Value
+
+			if opts.DryRun {
+				rep.Outcome = outcomeGenerated
+				rep.Value = message
+
+				return deliver(opts, message)
+			}
+
+			if !opts.NoInteractive {
+				prompt := "\n❓ Commit with this message? [Y/n/e/r]: "
+				if len(candidates) > 1 {
+					prompt = fmt.Sprintf("\n❓ Commit message [1-%d], e<N> to edit, r to regenerate, n to abort: ", len(candidates))
+				}
+
+				var (
+					action string
+					choice int
+				)
+				for {
+					fmt.Fprint(out, prompt)
+
+					action, choice, err = confirm(ctx, len(candidates))
+					if err != nil {
+						return fmt.Errorf("\nfailed to confirm: %w", err)
+					}
+
+					if action != actionInvalid {
+						break
+					}
+
+					fmt.Fprintln(out, color.Yellow("⚠️  Invalid choice, try again"))
+				}
+
+				message = candidates[choice].Value
+				rep.Commit = candidates[choice].Commit
+
+				switch action {
+				case actionAbort:
+					rep.Outcome = outcomeDeclined
+					fmt.Fprintln(out, color.Red("❌ Message not committed"))
+					return nil
+
+				case actionEdit:
+					message, err = editMessage(ctx, message)
+					if err != nil {
+						return fmt.Errorf(color.Red("Error: %w\n"), err)
+					}
+					rep.Commit = nil
+
+					if message == "" {
+						rep.Outcome = outcomeDeclined
+						fmt.Fprintln(out, color.Red("❌ Aborting commit due to empty commit message"))
+						return nil
+					}
+
+				case actionRegenerate:
+					fmt.Fprint(out, "💬 What should be changed? ")
+
+					feedback, err := readLine(ctx)
+					if err != nil {
+						return fmt.Errorf("\nfailed to read feedback: %w", err)
+					}
+
+					fmt.Fprintln(out, "\n🔄 Regenerating...")
+
+					resp, err = a.Regenerate(ctx, feedback)
+					if err != nil {
+						rep.Outcome = outcomeAgentError
+						return agentError(err)
+					}
+
+					continue
+				}
+			}
+
+			rep.Value = message
+
+			if err := deliver(opts, message); err != nil {
+				return fmt.Errorf(color.Red("Error: %w\n"), err)
+			}
+
+			if err := commitWithRetry(ctx, opts, a, message, rep); err != nil {
+				return fmt.Errorf("\nfailed to commit: %w", err)
+			}
+
+			fmt.Fprint(out, color.Green("✅ Successfully committed"))
 		}
 
-		if err := performCommit(ctx, resp.Value); err != nil {
-			return fmt.Errorf("\nfailed to commit: %w", err)
+		return nil
+	}
+}
+
+// agentError formats a failed run of the agent, with a hint for the errors
+// an option can fix.
+func agentError(err error) error {
+	var truncErr *agent.TruncatedError
+	if errors.As(err, &truncErr) {
+		return fmt.Errorf(color.Red("Error: %w, raise --max-completion-tokens\n"), err)
+	}
+
+	return fmt.Errorf(color.Red("Error: %w\n"), err)
+}
+
+// newProviders returns the provider writing the message and, with
+// --explore-model, the one exploring the repository. Retries and fallbacks
+// are emitted as events to hooks.
+func newProviders(opts *options, hooks *agent.Hooks) (llm.Provider, llm.Provider, error) {
+	if opts.Record != "" && opts.Replay != "" {
+		return nil, nil, errors.New("--record and --replay cannot be combined")
+	}
+
+	if opts.Replay != "" {
+		replayer, err := llm.NewReplayer(opts.Replay, opts.ReplayMatch, llm.ContextWindow(opts.Model.Models()[0]))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Both phases are served from the same cassette, in recorded order.
+		if opts.ExploreModel != "" {
+			return replayer, replayer, nil
 		}
 
-		fmt.Print(color.Green("✅ Successfully committed"))
+		return replayer, nil, nil
 	}
 
-	return nil
+	provider, err := newModelProvider(opts, opts.Model.Models(), hooks)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if opts.ExploreModel == "" {
+		return provider, nil, nil
+	}
+
+	explorer, err := newModelProvider(opts, opts.ExploreModel.Models(), hooks)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return provider, explorer, nil
 }
 
-func confirm(ctx context.Context) (bool, error) {
-	scanner := bufio.NewScanner(os.Stdin)
-	resultChan := make(chan string)
+// newModelProvider returns a provider for the models, falling back to the
+// next one when a model fails.
+func newModelProvider(opts *options, models []string, hooks *agent.Hooks) (llm.Provider, error) {
+	if len(models) == 0 {
+		return nil, errors.New("no model given")
+	}
 
-	go func() {
-		if scanner.Scan() {
-			resultChan <- scanner.Text()
+	onRetry := func(ctx context.Context, r *llm.Retry) {
+		hooks.Emit(ctx, &agent.RetryEvent{Attempt: r.Attempt, Delay: r.Delay, Err: r.Err})
+	}
+
+	onFallback := func(ctx context.Context, failed string, err error) {
+		hooks.Emit(ctx, &agent.FallbackEvent{Model: failed, Err: err})
+	}
+
+	chain := make([]llm.Model, len(models))
+	for i, name := range models {
+		chain[i] = llm.Model{
+			Name: name,
+			Provider: llm.NewRetrier(llm.NewOpenRouter(&llm.OpenRouterConfig{
+				APIKey:  opts.APIKey,
+				Model:   name,
+				Timeout: opts.Timeout,
+			}), &llm.RetryConfig{
+				MaxRetries: opts.Retries,
+				BaseDelay:  opts.RetryDelay,
+				MaxDelay:   opts.RetryMaxDelay,
+				OnRetry:    onRetry,
+			}),
 		}
-	}()
+	}
 
-	select {
-	case text := <-resultChan:
-		prepared := strings.ToLower(strings.TrimSpace(text))
-		if prepared == "n" || prepared == "no" {
-			return false, nil
-		} else {
-			return true, nil
+	var provider llm.Provider = chain[0].Provider
+	if len(chain) > 1 {
+		provider = llm.NewFallback(chain, onFallback)
+	}
+
+	if opts.Record != "" {
+		return llm.NewRecorder(provider, opts.Record)
+	}
+
+	return provider, nil
+}
+
+func resolveStyle(ctx context.Context, name string) (*style.Preset, error) {
+	if name == style.Auto {
+		subjects, err := git.RecentSubjects(ctx, 50)
+		if err != nil {
+			return nil, err
 		}
 
-	case <-ctx.Done():
-		return false, ctx.Err()
+		name = style.Detect(subjects)
+	}
+
+	return style.Get(name)
+}
+
+func branchTicket(ctx context.Context, pattern, placement string) (*ticket.Ticket, error) {
+	branch, err := git.CurrentBranch(ctx)
+	if err != nil {
+		return nil, err
 	}
+
+	return ticket.FromBranch(pattern, branch, placement)
 }
 
-func performCommit(ctx context.Context, message string) error {
-	cmd := exec.CommandContext(ctx, "git", "commit", "-m", message)
-	return cmd.Run()
+func buildTrailers(ctx context.Context, opts *options) ([]trailer.Trailer, error) {
+	var trailers []trailer.Trailer
+
+	if opts.SignOff {
+		t, err := trailer.SignOff(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		trailers = append(trailers, t)
+	}
+
+	if len(opts.Pairs) > 0 {
+		path := opts.PairsFile
+		if path == "" {
+			root, err := git.TopLevel(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			path = filepath.Join(root, ".pairs")
+		}
+
+		pairs, err := trailer.LoadPairs(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var aliases []string
+		for _, p := range opts.Pairs {
+			aliases = append(aliases, strings.Split(p, ",")...)
+		}
+
+		coAuthors, err := trailer.CoAuthors(pairs, aliases)
+		if err != nil {
+			return nil, err
+		}
+
+		trailers = append(trailers, coAuthors...)
+	}
+
+	for _, raw := range opts.Trailers {
+		t, err := trailer.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		trailers = append(trailers, t)
+	}
+
+	return trailers, nil
 }
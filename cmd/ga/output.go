@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/haadi-coder/color"
+	"golang.org/x/term"
+)
+
+// useStderr sends all human readable output to stderr, leaving stdout to the
+// message or report. Colors follow stderr instead of stdout.
+func useStderr() {
+	out = os.Stderr
+
+	if color.ForceColor {
+		return
+	}
+
+	if term.IsTerminal(int(os.Stderr.Fd())) && color.SupportsColor() {
+		color.ForceColor = true
+	} else {
+		color.NoColor = true
+	}
+}
+
+// deliver writes the final message to the message file and, with --print,
+// to stdout.
+func deliver(opts *options, message string) error {
+	if opts.MessageFile != "" {
+		if err := os.WriteFile(opts.MessageFile, []byte(message+"\n"), 0o644); err != nil {
+			return fmt.Errorf("failed to write message file: %w", err)
+		}
+	}
+
+	if opts.Print {
+		fmt.Fprintln(os.Stdout, message)
+	}
+
+	return nil
+}
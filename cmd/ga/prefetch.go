@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+
+	"github.com/haadi-coder/Git-Agent/internal/agent"
+	"github.com/haadi-coder/Git-Agent/internal/git"
+)
+
+const (
+	// fastDiffLimit is the staged diff size up to which the repository context
+	// is gathered up front even without --fast.
+	fastDiffLimit = 16 * 1024
+	// prefetchSubjects is the number of recent commit subjects gathered.
+	prefetchSubjects = 20
+)
+
+// prefetch gathers the status, staged diff and recent history for the first
+// user message when forced or when the diff is small. It returns nil when the
+// agent should explore on its own.
+func prefetch(ctx context.Context, force bool) (*agent.RepoContext, error) {
+	diff, err := git.Run(ctx, "diff", "--staged")
+	if err != nil {
+		return nil, err
+	}
+
+	if !force && len(diff) > fastDiffLimit {
+		return nil, nil
+	}
+
+	status, err := git.Run(ctx, "status", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+
+	subjects, err := git.RecentSubjects(ctx, prefetchSubjects)
+	if err != nil {
+		return nil, err
+	}
+
+	return &agent.RepoContext{
+		Status:   status,
+		Diff:     diff,
+		Subjects: subjects,
+	}, nil
+}
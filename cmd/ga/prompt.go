@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/haadi-coder/Git-Agent/internal/agent"
+	"github.com/haadi-coder/Git-Agent/internal/editor"
+	"github.com/haadi-coder/Git-Agent/internal/git"
+	"github.com/haadi-coder/color"
+)
+
+const (
+	actionCommit     = "commit"
+	actionAbort      = "abort"
+	actionEdit       = "edit"
+	actionRegenerate = "regenerate"
+	actionInvalid    = "invalid"
+)
+
+// confirm reads the answer to the commit prompt. For several candidates the
+// answer may select one by number, e.g. "2" or "e2". The returned index is
+// the selected candidate. An answer that selects no candidate returns
+// actionInvalid, so the caller can ask again.
+func confirm(ctx context.Context, candidates int) (string, int, error) {
+	text, err := readLine(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+
+	answer := strings.ToLower(strings.TrimSpace(text))
+
+	action := actionCommit
+	switch {
+	case answer == "" || answer == "y" || answer == "yes":
+		return actionCommit, 0, nil
+	case answer == "n" || answer == "no":
+		return actionAbort, 0, nil
+	case answer == "r" || answer == "regenerate":
+		return actionRegenerate, 0, nil
+	case answer == "e" || answer == "edit":
+		return actionEdit, 0, nil
+	case strings.HasPrefix(answer, "e"):
+		action = actionEdit
+		answer = strings.TrimPrefix(answer, "e")
+	}
+
+	choice, err := strconv.Atoi(answer)
+	if err != nil || choice < 1 || choice > candidates {
+		return actionInvalid, 0, nil
+	}
+
+	return action, choice - 1, nil
+}
+
+var stdin = bufio.NewReader(os.Stdin)
+
+func readLine(ctx context.Context) (string, error) {
+	resultChan := make(chan string, 1)
+	errChan := make(chan error, 1)
+
+	go func() {
+		text, err := stdin.ReadString('\n')
+		if err != nil && text == "" {
+			errChan <- err
+			return
+		}
+		resultChan <- strings.TrimSpace(text)
+	}()
+
+	select {
+	case text := <-resultChan:
+		return text, nil
+
+	case err := <-errChan:
+		return "", err
+
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func printCandidates(candidates []agent.Candidate) {
+	if len(candidates) == 1 {
+		fmt.Fprintln(out, color.Cyan("\n📜 Generated commit message:"))
+		fmt.Fprintln(out, candidates[0].Value)
+		return
+	}
+
+	fmt.Fprintln(out, color.Cyan("\n📜 Generated commit messages:"))
+	for i, c := range candidates {
+		fmt.Fprintf(out, "\n%s %s\n", color.Cyan(fmt.Sprintf("%d)", i+1)), strings.ReplaceAll(c.Value, "\n", "\n   "))
+		if c.Rationale != "" {
+			fmt.Fprintln(out, color.Black("   💡 "+c.Rationale))
+		}
+	}
+}
+
+func editMessage(ctx context.Context, message string) (string, error) {
+	stat, err := git.Run(ctx, "diff", "--staged", "--stat")
+	if err != nil {
+		return "", fmt.Errorf("failed to get diffstat: %w", err)
+	}
+
+	// With --print or --output json stdout is reserved for the result, so
+	// the editor draws on the terminal directly.
+	stdout := io.Writer(os.Stdout)
+	if out != os.Stdout {
+		stdout = os.Stderr
+
+		if tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+			defer tty.Close()
+			stdout = tty
+		}
+	}
+
+	return editor.Edit(ctx, message, "Changes to be committed:\n"+stat, stdout)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+
+	"github.com/haadi-coder/Git-Agent/internal/agent"
+	"github.com/haadi-coder/Git-Agent/internal/style"
+)
+
+const (
+	outcomeCommitted     = "committed"
+	outcomeGenerated     = "generated"
+	outcomeDeclined      = "declined"
+	outcomeSuggestion    = "suggestion"
+	outcomeAgentError    = "agent_error"
+	outcomeNothingStaged = "nothing_staged"
+	outcomeError         = "error"
+)
+
+// exitCodes maps the outcome of a run to the process exit code.
+var exitCodes = map[string]int{
+	outcomeCommitted:     0,
+	outcomeGenerated:     0,
+	outcomeError:         1,
+	outcomeDeclined:      2,
+	outcomeSuggestion:    3,
+	outcomeAgentError:    4,
+	outcomeNothingStaged: 5,
+}
+
+// report describes a run for --output json.
+type report struct {
+	Outcome   string           `json:"outcome"`
+	Type      string           `json:"type,omitempty"`
+	Value     string           `json:"value,omitempty"`
+	Commit    *style.Message   `json:"commit,omitempty"`
+	Model     string           `json:"model"`
+	Usage     agent.Usage      `json:"usage"`
+	Cached    bool             `json:"cached"`
+	ToolCalls []toolCallReport `json:"tool_calls"`
+	Committed bool             `json:"committed"`
+	CommitSHA string           `json:"commit_sha,omitempty"`
+	Error     string           `json:"error,omitempty"`
+}
+
+type toolCallReport struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+func (r *report) exitCode() int {
+	return exitCodes[r.Outcome]
+}
+
+var ansiRgx = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+func (r *report) write(w io.Writer, err error) error {
+	if err != nil {
+		r.Error = ansiRgx.ReplaceAllString(err.Error(), "")
+	}
+
+	if r.ToolCalls == nil {
+		r.ToolCalls = []toolCallReport{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(r)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/haadi-coder/Git-Agent/internal/agent"
+	"github.com/haadi-coder/Git-Agent/internal/git"
+	"github.com/haadi-coder/Git-Agent/internal/trailer"
+	"github.com/haadi-coder/Git-Agent/internal/tui"
+	"github.com/haadi-coder/color"
+	"github.com/rivo/tview"
+)
+
+func tuiHooks(ui *tui.UI, opts *options) *agent.Hooks {
+	hooks := &agent.Hooks{}
+
+	streaming := false
+
+	agent.Subscribe(hooks, func(ctx context.Context, e *agent.ContentDeltaEvent) {
+		if !streaming {
+			ui.Append("[yellow]Agent:[-] ")
+			streaming = true
+		}
+
+		ui.Append(tview.Escape(e.Delta))
+	})
+
+	agent.Subscribe(hooks, func(ctx context.Context, e *agent.MessageDeltaEvent) {
+		ui.AppendMessage(e.Delta)
+	})
+
+	agent.Subscribe(hooks, func(ctx context.Context, e *agent.LLMResponseEvent) {
+		message := e.Response.Choices[0].Message
+		if len(message.ToolCalls) == 0 || message.Content == "" {
+			return
+		}
+
+		if !opts.NoStream {
+			ui.Logf("")
+			streaming = false
+			return
+		}
+
+		ui.Logf("[yellow]Agent:[-] %s", tview.Escape(message.Content))
+	})
+
+	agent.Subscribe(hooks, func(ctx context.Context, e *agent.ToolStartEvent) {
+		ui.Logf("[blue]Tool:[-] %s(%s)", e.ToolCall.Function.Name, tview.Escape(e.ToolCall.Function.Arguments))
+	})
+
+	agent.Subscribe(hooks, func(ctx context.Context, e *agent.ToolEndEvent) {
+		if e.Result.Err != nil {
+			ui.Logf("[gray]  %s failed in %s: %s[-]", e.ToolCall.Function.Name, e.Result.Duration.Round(time.Millisecond), tview.Escape(e.Result.Err.Error()))
+			return
+		}
+
+		ui.Logf("[gray]  %s done in %s[-]", e.ToolCall.Function.Name, e.Result.Duration.Round(time.Millisecond))
+	})
+
+	agent.Subscribe(hooks, func(ctx context.Context, e *agent.ValidationFailedEvent) {
+		ui.ClearMessage()
+		ui.Logf("[yellow]Message rejected, asking agent to fix:[-]")
+		for _, v := range e.Violations {
+			ui.Logf("[gray] - %s[-]", tview.Escape(v))
+		}
+	})
+
+	agent.Subscribe(hooks, func(ctx context.Context, e *agent.RetryEvent) {
+		ui.Logf("[yellow]Retry %d/%d in %s:[-] %s", e.Attempt, opts.Retries, e.Delay.Round(time.Millisecond), tview.Escape(e.Err.Error()))
+	})
+
+	agent.Subscribe(hooks, func(ctx context.Context, e *agent.FallbackEvent) {
+		ui.Logf("[yellow]%s failed, trying the next model:[-] %s", tview.Escape(e.Model), tview.Escape(e.Err.Error()))
+	})
+
+	agent.Subscribe(hooks, func(ctx context.Context, e *agent.FinalEvent) {
+		if e.Model != "" {
+			ui.Logf("[gray]Written by %s, %d tokens used in total[-]", tview.Escape(e.Model), e.Usage.TotalTokens)
+			return
+		}
+
+		ui.Logf("[gray]%d tokens used in total[-]", e.Usage.TotalTokens)
+	})
+
+	return hooks
+}
+
+func runTUI(ctx context.Context, ui *tui.UI, a *agent.Agent, rc *responseCache, trailers []trailer.Trailer, opts *options, rep *report) error {
+	diff, err := git.Run(ctx, "diff", "--staged")
+	if err != nil {
+		return fmt.Errorf(color.Red("Error: %w\n"), err)
+	}
+
+	// candidates are the alternatives of the last response, if there are
+	// several.
+	var candidates []agent.Candidate
+
+	committed, err := ui.Run(ctx, diff, tui.Handlers{
+		Generate: func(ctx context.Context, feedback string) (*agent.Response, error) {
+			var (
+				resp *agent.Response
+				err  error
+			)
+			if feedback == "" {
+				var cached bool
+				resp, cached, err = rc.run(ctx, a)
+				if cached {
+					rep.Cached = true
+					ui.Logf("[gray]⚡ Using the cached message for these changes, run with --no-cache for a new one[-]")
+				}
+			} else {
+				resp, err = a.Regenerate(ctx, feedback)
+			}
+			if err != nil {
+				rep.Outcome = outcomeAgentError
+				return nil, err
+			}
+
+			rep.Type = resp.Type
+			rep.Value = resp.Value
+			rep.Commit = resp.Commit
+
+			// The outcome follows the last response, like in the line UI. A
+			// message that is never committed counts as declined.
+			switch resp.Type {
+			case agent.ResponseTypeError, agent.ResponseTypeBudgetExhausted:
+				rep.Outcome = outcomeAgentError
+			case agent.ResponseTypeSuggestion:
+				rep.Outcome = outcomeSuggestion
+			default:
+				rep.Outcome = outcomeDeclined
+			}
+
+			if resp.Type == agent.ResponseTypeResult {
+				resp.Value, err = trailer.Apply(ctx, resp.Value, trailers)
+				if err != nil {
+					return nil, err
+				}
+
+				for i := range resp.Candidates {
+					resp.Candidates[i].Value, err = trailer.Apply(ctx, resp.Candidates[i].Value, trailers)
+					if err != nil {
+						return nil, err
+					}
+				}
+			}
+
+			candidates = resp.Candidates
+
+			return resp, nil
+		},
+		Commit: func(ctx context.Context, message string) error {
+			rep.Value = message
+
+			// The fields are only reported for an unedited candidate.
+			if len(candidates) > 0 {
+				rep.Commit = nil
+				for _, c := range candidates {
+					if strings.TrimSpace(c.Value) == message {
+						rep.Commit = c.Commit
+					}
+				}
+			}
+
+			if err := deliver(opts, message); err != nil {
+				rep.Outcome = outcomeError
+				return err
+			}
+
+			if err := commit(ctx, opts, message, rep); err != nil {
+				rep.Outcome = outcomeError
+				return err
+			}
+
+			return nil
+		},
+	})
+	if err != nil {
+		return fmt.Errorf(color.Red("Error: %w\n"), err)
+	}
+
+	if !committed {
+		if rep.Outcome == "" {
+			rep.Outcome = outcomeDeclined
+		}
+		fmt.Println(color.Red("❌ Message not committed"))
+		return nil
+	}
+
+	fmt.Println(color.Green("✅ Successfully committed"))
+
+	return nil
+}
@@ -3,20 +3,27 @@ module github.com/haadi-coder/Git-Agent
 go 1.24.5
 
 require (
+	github.com/gdamore/tcell/v2 v2.8.1
 	github.com/haadi-coder/color v0.0.0-20250715144945-4377a95e2f6b
 	github.com/jessevdk/go-flags v1.6.1
 	github.com/openai/openai-go v1.12.0
+	github.com/rivo/tview v0.42.0
 	github.com/stretchr/testify v1.10.0
+	golang.org/x/term v0.33.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/gdamore/encoding v1.0.1 // indirect
+	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
+	github.com/mattn/go-runewidth v0.0.16 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/rivo/uniseg v0.4.7 // indirect
 	github.com/tidwall/gjson v1.14.4 // indirect
 	github.com/tidwall/match v1.1.1 // indirect
 	github.com/tidwall/pretty v1.2.1 // indirect
 	github.com/tidwall/sjson v1.2.5 // indirect
 	golang.org/x/sys v0.34.0 // indirect
-	golang.org/x/term v0.33.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	golang.org/x/text v0.21.0 // indirect
 )
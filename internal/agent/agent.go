@@ -2,14 +2,24 @@ package agent
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/haadi-coder/Git-Agent/internal/llm"
+	"github.com/haadi-coder/Git-Agent/internal/style"
 	"github.com/haadi-coder/Git-Agent/internal/tool"
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/shared"
 )
 
+// maxParallelTools limits the number of tool calls running at the same time.
+const maxParallelTools = 4
+
 var tools = [5]tool.Tool{
 	&tool.Read{},
 	&tool.LS{},
@@ -37,87 +47,370 @@ func init() {
 }
 
 type Agent struct {
-	llm          *llm.OpenRouter
-	systemPrompt string
-	hooks        *Hooks
+	llm                 llm.Provider
+	explorer            llm.Provider
+	systemPrompt        string
+	responseFormat      *openai.ChatCompletionNewParamsResponseFormatUnion
+	style               *style.Preset
+	validators          []Validator
+	stream              bool
+	hooks               *Hooks
+	history             []openai.ChatCompletionMessageParamUnion
+	usage               Usage
+	repoContext         *RepoContext
+	maxTokens           int64
+	maxSteps            int
+	maxCompletionTokens int64
+	contextWindow       int
+	steps               int
+	wrappingUp          bool
+	model               string
+}
+
+// Usage is the token usage summed over all model calls of the agent.
+type Usage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+}
+
+type Config struct {
+	Instructions []string
+	Validators   []Validator
+	// Style is the commit style preset used to render the message. When nil,
+	// the agent infers the style from the repository history.
+	Style *style.Preset
+	// Candidates is the number of alternative messages to generate.
+	Candidates int
+	// Stream enables streaming of the model output to the delta hooks.
+	Stream bool
+	// MaxTokens is the token budget of the session, summed over all model
+	// calls. Zero means no limit.
+	MaxTokens int64
+	// MaxSteps limits the number of model calls in the session. Zero means
+	// no limit.
+	MaxSteps int
+	// MaxCompletionTokens caps the completion of a single model call,
+	// including the reasoning tokens of reasoning models. Zero means 4096.
+	MaxCompletionTokens int64
+	// RepoContext is repository information gathered up front and sent with
+	// the first request, so the model can usually answer without tools.
+	RepoContext *RepoContext
+	// Explorer, if set, serves the exploration and tool calling steps,
+	// usually with a cheaper model. The final message is written by the
+	// main provider, without tools, from what the explorer gathered.
+	Explorer llm.Provider
+}
+
+// RepoContext is a snapshot of the repository state for the first request.
+type RepoContext struct {
+	Status   string
+	Diff     string
+	Subjects []string
 }
 
-func NewAgent(llm *llm.OpenRouter, instructions []string, hooks *Hooks) (*Agent, error) {
-	systemPrompt, err := buildSystemPrompt(instructions)
+func NewAgent(llm llm.Provider, cfg *Config, hooks *Hooks) (*Agent, error) {
+	systemPrompt, err := buildSystemPrompt(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build system prompt: %w", err)
 	}
 
+	// Without a preset the model still returns the message fields, with a
+	// subject line of its own, so the message is always rendered the same way.
+	preset := style.Inferred()
+
+	validators := cfg.Validators
+	if cfg.Style != nil {
+		preset = cfg.Style
+		validators = append([]Validator{cfg.Style.Validate}, validators...)
+	}
+
 	return &Agent{
-		llm:          llm,
-		systemPrompt: systemPrompt,
-		hooks:        hooks,
+		llm:                 llm,
+		explorer:            cfg.Explorer,
+		systemPrompt:        systemPrompt,
+		responseFormat:      newResponseFormat(preset, cfg.Candidates),
+		style:               preset,
+		validators:          validators,
+		stream:              cfg.Stream,
+		hooks:               hooks,
+		repoContext:         cfg.RepoContext,
+		maxTokens:           cfg.MaxTokens,
+		maxSteps:            cfg.MaxSteps,
+		maxCompletionTokens: cfg.MaxCompletionTokens,
+		contextWindow:       contextWindow(llm, cfg.Explorer),
 	}, nil
 }
 
 func (a *Agent) Run(ctx context.Context) (*Response, error) {
+	a.history = a.initialHistory()
+
+	return a.run(ctx, RunReasonGenerate)
+}
+
+// Restore continues from a response of an earlier session, e.g. one read
+// from the cache, so that Regenerate and ExplainCommitFailure can follow it.
+func (a *Agent) Restore(resp *Response) error {
+	content, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	a.history = append(a.initialHistory(), openai.AssistantMessage(string(content)))
+
+	return nil
+}
+
+// PromptVersion identifies the system prompt and response format, so cached
+// responses are not reused after either changes.
+func (a *Agent) PromptVersion() string {
+	format, _ := json.Marshal(a.responseFormat)
+
+	sum := sha256.Sum256([]byte(a.systemPrompt + string(format)))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+func (a *Agent) initialHistory() []openai.ChatCompletionMessageParamUnion {
 	history := []openai.ChatCompletionMessageParamUnion{
 		openai.SystemMessage(a.systemPrompt),
 	}
 
+	if a.repoContext != nil {
+		history = append(history, openai.UserMessage(repoContextMessage(a.repoContext)))
+	}
+
+	return history
+}
+
+// Regenerate asks for a new response taking the user feedback into account.
+// It continues the conversation of the previous Run, so the repository does
+// not have to be explored again.
+func (a *Agent) Regenerate(ctx context.Context, feedback string) (*Response, error) {
+	if len(a.history) == 0 {
+		return nil, fmt.Errorf("nothing to regenerate, agent has not been run")
+	}
+
+	a.history = append(a.history, openai.UserMessage(regenerateFeedback(feedback)))
+
+	return a.run(ctx, RunReasonRegenerate)
+}
+
+// ExplainCommitFailure gives the output of a failed commit back to the agent
+// and asks for an explanation and a fix, returned as a suggestion.
+func (a *Agent) ExplainCommitFailure(ctx context.Context, output string) (*Response, error) {
+	if len(a.history) == 0 {
+		return nil, fmt.Errorf("nothing to explain, agent has not been run")
+	}
+
+	a.history = append(a.history, openai.UserMessage(commitFailureFeedback(output)))
+
+	return a.run(ctx, RunReasonCommitFailure)
+}
+
+func (a *Agent) Usage() Usage {
+	return a.usage
+}
+
+// Model returns the model that produced the last response, as reported by
+// the provider.
+func (a *Agent) Model() string {
+	return a.model
+}
+
+// contextWindow returns the smaller context window of the two providers.
+func contextWindow(main, explorer llm.Provider) int {
+	if explorer == nil {
+		return main.ContextWindow()
+	}
+
+	return min(main.ContextWindow(), explorer.ContextWindow())
+}
+
+// run emits the events around a run of the loop.
+func (a *Agent) run(ctx context.Context, reason string) (*Response, error) {
+	a.hooks.Emit(ctx, &RunStartEvent{Reason: reason})
+
+	resp, err := a.loop(ctx)
+	if err != nil {
+		a.hooks.Emit(ctx, &ErrorEvent{Err: err})
+		return nil, err
+	}
+
+	a.hooks.Emit(ctx, &FinalEvent{Response: resp, Model: a.model, Usage: a.usage})
+
+	return resp, nil
+}
+
+func (a *Agent) loop(ctx context.Context) (*Response, error) {
+	attempts := 0
+	exploring := a.explorer != nil
+
 	for {
-		resp, err := a.llm.GenerateContent(ctx, openai.ChatCompletionNewParams{
-			Messages:       history,
-			Tools:          openaiTools,
-			ResponseFormat: *responseFormat,
-		})
+		if !a.wrappingUp && a.nearLimit() {
+			a.wrappingUp = true
+			a.history = append(a.history, openai.UserMessage(wrapUpInstruction))
+		}
+
+		a.compactHistory()
+
+		// The prompt of the call counts against the budget as well, so the
+		// check follows the compaction.
+		if a.exhausted() {
+			return a.budgetExhausted(), nil
+		}
+
+		provider := a.llm
+		explorerStep := exploring && !a.wrappingUp
+		if explorerStep {
+			provider = a.explorer
+		}
+
+		limit := a.completionLimit()
+		params := openai.ChatCompletionNewParams{
+			Messages:       a.history,
+			ResponseFormat: *a.responseFormat,
+			MaxTokens:      openai.Int(limit),
+		}
+		if !a.wrappingUp && (a.explorer == nil || explorerStep) {
+			params.Tools = openaiTools
+		}
+
+		a.hooks.Emit(ctx, &LLMRequestEvent{Step: a.steps + 1, Explorer: explorerStep, Params: &params})
+
+		start := time.Now()
+		resp, err := a.generate(ctx, provider, params, !explorerStep)
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate content: %w", err)
 		}
 
+		a.steps++
+		a.hooks.Emit(ctx, &LLMResponseEvent{Step: a.steps, Explorer: explorerStep, Response: resp, Duration: time.Since(start)})
+		a.usage.PromptTokens += resp.Usage.PromptTokens
+		a.usage.CompletionTokens += resp.Usage.CompletionTokens
+		a.usage.TotalTokens += resp.Usage.TotalTokens
+
+		// A response cut off at the completion limit can't be parsed. It is
+		// only a matter of the budget if the budget lowered the limit.
+		if resp.Choices[0].FinishReason == "length" {
+			if limit < a.completionCap() {
+				return a.budgetExhausted(), nil
+			}
+
+			return nil, &TruncatedError{Limit: limit}
+		}
+
 		message := resp.Choices[0].Message
 
 		isFinalStep := len(message.ToolCalls) == 0
+		if isFinalStep && explorerStep {
+			// The explorer's answer is dropped, the main model writes the
+			// final response from the same history.
+			exploring = false
+			continue
+		}
+
 		if isFinalStep {
 			parsed, err := parseResponse(message.Content)
 			if err != nil {
 				return nil, fmt.Errorf("failed to parse response: %w", err)
 			}
 
+			if parsed.Type == ResponseTypeResult {
+				a.render(parsed)
+
+				if violations := a.validateResponse(parsed); len(violations) > 0 {
+					if attempts >= maxValidationAttempts {
+						return nil, fmt.Errorf("generated message is invalid: %s", strings.Join(violations, "; "))
+					}
+					attempts++
+
+					a.hooks.Emit(ctx, &ValidationFailedEvent{Violations: violations})
+
+					a.history = append(a.history, message.ToParam(), openai.UserMessage(validationFeedback(violations)))
+					continue
+				}
+			}
+
+			a.history = append(a.history, message.ToParam())
+			a.model = resp.Model
+
 			return parsed, nil
 		}
 
-		a.hooks.handleIntermidiateStep(ctx, resp)
-
-		history = append(history, message.ToParam())
+		a.history = append(a.history, message.ToParam())
 
 		toolResults := a.callTools(ctx, message.ToolCalls)
-		history = append(history, toolResults...)
+		a.history = append(a.history, toolResults...)
+	}
+}
 
-		a.hooks.handleAfterIntermidiateStep(ctx, resp)
+// generate calls the provider. showMessage controls whether a streamed
+// response is passed to the message delta hooks.
+func (a *Agent) generate(ctx context.Context, provider llm.Provider, params openai.ChatCompletionNewParams, showMessage bool) (*openai.ChatCompletion, error) {
+	if !a.stream {
+		return provider.GenerateContent(ctx, params)
 	}
+
+	router := newDeltaRouter(ctx, a.hooks, showMessage)
+
+	return provider.GenerateContentStream(ctx, params, router.write)
 }
 
+// callTools runs the tool calls concurrently, at most maxParallelTools at a
+// time, and returns their results in the order of the calls.
 func (a *Agent) callTools(ctx context.Context, toolCalls []openai.ChatCompletionMessageToolCall) []openai.ChatCompletionMessageParamUnion {
 	toolResults := make([]openai.ChatCompletionMessageParamUnion, len(toolCalls))
 
-	for i, toolCall := range toolCalls {
-		a.hooks.handleBeforeCallTool(ctx, &toolCall)
+	sem := make(chan struct{}, maxParallelTools)
+	var wg sync.WaitGroup
 
-		var toolResult string
-		name := toolCall.Function.Name
-		args := toolCall.Function.Arguments
+	for i, toolCall := range toolCalls {
+		wg.Add(1)
 
-		tool, ok := toolLookup[name]
+		go func() {
+			defer wg.Done()
 
-		if !ok {
-			toolResult = fmt.Sprintf("Unknown tool: %s", name)
-		} else {
-			result, err := tool.Call(ctx, args)
-			if err != nil {
-				toolResult = fmt.Sprintf("Error: %s", err.Error())
-			} else {
-				toolResult = result
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				toolResults[i] = openai.ToolMessage(fmt.Sprintf("Error: %s", ctx.Err()), toolCall.ID)
+				return
 			}
-		}
 
-		toolResults[i] = openai.ToolMessage(toolResult, toolCall.ID)
+			toolResults[i] = openai.ToolMessage(a.callTool(ctx, &toolCall), toolCall.ID)
+		}()
 	}
 
+	wg.Wait()
+
 	return toolResults
 }
+
+func (a *Agent) callTool(ctx context.Context, toolCall *openai.ChatCompletionMessageToolCall) string {
+	a.hooks.Emit(ctx, &ToolStartEvent{ToolCall: toolCall})
+
+	name := toolCall.Function.Name
+	args := toolCall.Function.Arguments
+
+	start := time.Now()
+	result := &ToolResult{}
+
+	if t, ok := toolLookup[name]; !ok {
+		result.Err = fmt.Errorf("unknown tool: %s", name)
+	} else if err := tool.ValidateArgs(t.Params(), args); err != nil {
+		result.Err = err
+	} else {
+		result.Output, result.Err = t.Call(ctx, args)
+	}
+	result.Duration = time.Since(start)
+
+	a.hooks.Emit(ctx, &ToolEndEvent{ToolCall: toolCall, Result: result})
+
+	if result.Err != nil {
+		return truncateToolResult(name, fmt.Sprintf("Error: %s", result.Err.Error()))
+	}
+
+	return truncateToolResult(name, result.Output)
+}
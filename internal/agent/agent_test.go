@@ -0,0 +1,277 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/haadi-coder/Git-Agent/internal/llm"
+	"github.com/haadi-coder/Git-Agent/internal/style"
+	"github.com/haadi-coder/Git-Agent/internal/tool"
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent_Run(t *testing.T) {
+	replayer, err := llm.NewReplayer("testdata/run.jsonl", llm.MatchSequence, 128_000)
+	require.NoError(t, err)
+
+	preset, err := style.Get(style.Conventional)
+	require.NoError(t, err)
+
+	var (
+		tools      []string
+		violations []string
+		kinds      []string
+		final      *FinalEvent
+	)
+
+	hooks := &Hooks{}
+	Subscribe(hooks, func(ctx context.Context, e *ToolStartEvent) {
+		tools = append(tools, e.ToolCall.Function.Name)
+	})
+	Subscribe(hooks, func(ctx context.Context, e *ValidationFailedEvent) {
+		violations = append(violations, e.Violations...)
+	})
+	Subscribe(hooks, func(ctx context.Context, e *FinalEvent) {
+		final = e
+	})
+	hooks.SubscribeAll(func(ctx context.Context, e Event) {
+		kinds = append(kinds, e.Kind())
+	})
+
+	a, err := NewAgent(replayer, &Config{Style: preset, Candidates: 1, Stream: true}, hooks)
+	require.NoError(t, err)
+
+	resp, err := a.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, ResponseTypeResult, resp.Type)
+	assert.Equal(t, "feat: add replay support\n\nExplain the change.", resp.Value)
+	assert.Equal(t, []string{"list_files"}, tools)
+	assert.Equal(t, []string{`type "feature" is not allowed, must be one of: feat, fix, docs, style, refactor, perf, test, build, ci, chore, revert`}, violations)
+	assert.Equal(t, Usage{PromptTokens: 270, CompletionTokens: 30, TotalTokens: 300}, a.Usage())
+
+	assert.Equal(t, []string{
+		"run_start",
+		"llm_request", "llm_response", "tool_start", "tool_end",
+		"llm_request", "message_delta", "llm_response", "validation_failed",
+		"llm_request", "message_delta", "llm_response",
+		"final",
+	}, kinds)
+	require.NotNil(t, final)
+	assert.Same(t, resp, final.Response)
+	assert.Equal(t, a.Usage(), final.Usage)
+}
+
+func TestAgent_Run_Truncated(t *testing.T) {
+	t.Run("limited by the budget", func(t *testing.T) {
+		replayer, err := llm.NewReplayer("testdata/truncated.jsonl", llm.MatchSequence, 128_000)
+		require.NoError(t, err)
+
+		a, err := NewAgent(replayer, &Config{Candidates: 1, MaxTokens: 3_000}, &Hooks{})
+		require.NoError(t, err)
+
+		resp, err := a.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, ResponseTypeBudgetExhausted, resp.Type)
+	})
+
+	t.Run("limited by the completion cap", func(t *testing.T) {
+		replayer, err := llm.NewReplayer("testdata/truncated.jsonl", llm.MatchSequence, 128_000)
+		require.NoError(t, err)
+
+		a, err := NewAgent(replayer, &Config{Candidates: 1}, &Hooks{})
+		require.NoError(t, err)
+
+		_, err = a.Run(context.Background())
+
+		var truncErr *TruncatedError
+		require.ErrorAs(t, err, &truncErr)
+		assert.Equal(t, int64(defaultCompletionTokens), truncErr.Limit)
+	})
+}
+
+func TestAgent_Run_Error(t *testing.T) {
+	replayer, err := llm.NewReplayer("testdata/writer.jsonl", llm.MatchSequence, 128_000)
+	require.NoError(t, err)
+
+	var errs []error
+
+	hooks := &Hooks{}
+	Subscribe(hooks, func(ctx context.Context, e *ErrorEvent) {
+		errs = append(errs, e.Err)
+	})
+
+	a, err := NewAgent(replayer, &Config{Candidates: 1}, hooks)
+	require.NoError(t, err)
+
+	_, err = a.Run(context.Background())
+	require.NoError(t, err)
+
+	_, err = a.Regenerate(context.Background(), "shorter")
+	require.Error(t, err)
+	assert.Equal(t, []error{err}, errs)
+}
+
+// toolsSpy records the number of tools offered with every request.
+type toolsSpy struct {
+	llm.Provider
+	tools []int
+}
+
+func (s *toolsSpy) GenerateContentStream(ctx context.Context, params openai.ChatCompletionNewParams, onDelta func(delta string)) (*openai.ChatCompletion, error) {
+	s.tools = append(s.tools, len(params.Tools))
+
+	return s.Provider.GenerateContentStream(ctx, params, onDelta)
+}
+
+func TestAgent_Run_Explorer(t *testing.T) {
+	explorer, err := llm.NewReplayer("testdata/explorer.jsonl", llm.MatchSequence, 128_000)
+	require.NoError(t, err)
+
+	writer, err := llm.NewReplayer("testdata/writer.jsonl", llm.MatchSequence, 128_000)
+	require.NoError(t, err)
+
+	explorerSpy := &toolsSpy{Provider: explorer}
+	writerSpy := &toolsSpy{Provider: writer}
+
+	var messages []string
+
+	hooks := &Hooks{}
+	Subscribe(hooks, func(ctx context.Context, e *MessageDeltaEvent) {
+		messages = append(messages, e.Delta)
+	})
+
+	a, err := NewAgent(writerSpy, &Config{Candidates: 1, Stream: true, Explorer: explorerSpy}, hooks)
+	require.NoError(t, err)
+
+	resp, err := a.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "Add replay support", resp.Value)
+	assert.Equal(t, "strong", a.Model())
+	assert.Equal(t, []string{"Add replay support"}, messages)
+	assert.Equal(t, []int{len(tools), len(tools)}, explorerSpy.tools)
+	assert.Equal(t, []int{0}, writerSpy.tools)
+	assert.Equal(t, int64(300), a.Usage().TotalTokens)
+}
+
+// sleepTool echoes its input after a delay and records the peak number of
+// concurrent calls.
+type sleepTool struct {
+	delay   time.Duration
+	running atomic.Int32
+	peak    atomic.Int32
+}
+
+func (s *sleepTool) Name() string           { return "sleep" }
+func (s *sleepTool) Description() string    { return "" }
+func (s *sleepTool) Params() map[string]any { return nil }
+
+func (s *sleepTool) Call(ctx context.Context, input string) (string, error) {
+	n := s.running.Add(1)
+	defer s.running.Add(-1)
+
+	for {
+		peak := s.peak.Load()
+		if n <= peak || s.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+
+	select {
+	case <-time.After(s.delay):
+		return input, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func TestAgent_CallTools(t *testing.T) {
+	st := &sleepTool{delay: 20 * time.Millisecond}
+	toolLookup[st.Name()] = st
+	defer delete(toolLookup, st.Name())
+
+	var toolCalls []openai.ChatCompletionMessageToolCall
+	for i := range 10 {
+		toolCalls = append(toolCalls, openai.ChatCompletionMessageToolCall{
+			ID:       fmt.Sprintf("call_%d", i),
+			Function: openai.ChatCompletionMessageToolCallFunction{Name: "sleep", Arguments: fmt.Sprintf(`"out %d"`, i)},
+		})
+	}
+	toolCalls = append(toolCalls, openai.ChatCompletionMessageToolCall{
+		ID:       "call_unknown",
+		Function: openai.ChatCompletionMessageToolCallFunction{Name: "missing"},
+	})
+
+	hooks := &Hooks{}
+	var durations []time.Duration
+	Subscribe(hooks, func(ctx context.Context, e *ToolEndEvent) {
+		durations = append(durations, e.Result.Duration)
+	})
+
+	a := &Agent{hooks: hooks}
+	results := a.callTools(context.Background(), toolCalls)
+
+	require.Len(t, results, len(toolCalls))
+	for i, r := range results {
+		assert.Equal(t, toolCalls[i].ID, r.OfTool.ToolCallID)
+	}
+	assert.Equal(t, `"out 3"`, results[3].OfTool.Content.OfString.Value)
+	assert.Equal(t, "Error: unknown tool: missing", results[10].OfTool.Content.OfString.Value)
+
+	assert.LessOrEqual(t, st.peak.Load(), int32(maxParallelTools))
+	assert.Greater(t, st.peak.Load(), int32(1))
+	assert.Len(t, durations, len(toolCalls))
+}
+
+func TestAgent_CallTools_InvalidArgs(t *testing.T) {
+	var results []*ToolResult
+
+	hooks := &Hooks{}
+	Subscribe(hooks, func(ctx context.Context, e *ToolEndEvent) {
+		results = append(results, e.Result)
+	})
+
+	a := &Agent{hooks: hooks}
+	messages := a.callTools(context.Background(), []openai.ChatCompletionMessageToolCall{{
+		ID:       "call_1",
+		Function: openai.ChatCompletionMessageToolCallFunction{Name: "read_file", Arguments: `{"file":"go.mod"}`},
+	}})
+
+	require.Len(t, messages, 1)
+	assert.Equal(t, `Error: invalid arguments: missing required field "path"; unknown field "file", allowed fields are: path`, messages[0].OfTool.Content.OfString.Value)
+
+	require.Len(t, results, 1)
+	var argsErr *tool.ArgsError
+	require.ErrorAs(t, results[0].Err, &argsErr)
+	assert.Len(t, argsErr.Problems, 2)
+}
+
+func TestAgent_CallTools_Cancelled(t *testing.T) {
+	st := &sleepTool{delay: time.Minute}
+	toolLookup[st.Name()] = st
+	defer delete(toolLookup, st.Name())
+
+	toolCalls := make([]openai.ChatCompletionMessageToolCall, 8)
+	for i := range toolCalls {
+		toolCalls[i] = openai.ChatCompletionMessageToolCall{
+			ID:       fmt.Sprintf("call_%d", i),
+			Function: openai.ChatCompletionMessageToolCallFunction{Name: "sleep", Arguments: "{}"},
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	a := &Agent{hooks: &Hooks{}}
+	results := a.callTools(ctx, toolCalls)
+
+	for _, r := range results {
+		assert.Contains(t, r.OfTool.Content.OfString.Value, "context deadline exceeded")
+	}
+}
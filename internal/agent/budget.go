@@ -0,0 +1,73 @@
+package agent
+
+import "fmt"
+
+const (
+	// defaultCompletionTokens caps the completion of a single model call
+	// when Config.MaxCompletionTokens is not set.
+	defaultCompletionTokens = 4096
+	// minCompletionTokens is the part of the token budget kept for the
+	// final answer. With less left, the model is not called again, as the
+	// answer would be cut off.
+	minCompletionTokens = 512
+	// wrapUpRatio is the share of the token budget after which the agent is
+	// asked to finalize without tools.
+	wrapUpRatio = 0.8
+)
+
+// exhausted reports whether the session has used up its token budget or
+// step limit. The token budget counts as used up when less than
+// minCompletionTokens are left after the prompt of the next call.
+func (a *Agent) exhausted() bool {
+	return (a.maxTokens > 0 && a.remainingTokens() < minCompletionTokens) ||
+		(a.maxSteps > 0 && a.steps >= a.maxSteps)
+}
+
+// nearLimit reports whether the next model call should be the last one.
+func (a *Agent) nearLimit() bool {
+	return (a.maxTokens > 0 && float64(a.usage.TotalTokens) >= wrapUpRatio*float64(a.maxTokens)) ||
+		(a.maxSteps > 0 && a.steps >= a.maxSteps-1)
+}
+
+// remainingTokens returns the token budget left for the completion of the
+// next call, after the estimated tokens of its prompt.
+func (a *Agent) remainingTokens() int64 {
+	return a.maxTokens - a.usage.TotalTokens - int64(historyTokens(a.history))
+}
+
+// completionCap returns the completion token limit of a single call.
+func (a *Agent) completionCap() int64 {
+	if a.maxCompletionTokens > 0 {
+		return a.maxCompletionTokens
+	}
+
+	return defaultCompletionTokens
+}
+
+// completionLimit returns the completion token limit for the next call: the
+// per-call cap, or less when the session budget is nearly used.
+func (a *Agent) completionLimit() int64 {
+	if a.maxTokens <= 0 {
+		return a.completionCap()
+	}
+
+	return max(min(a.remainingTokens(), a.completionCap()), 1)
+}
+
+func (a *Agent) budgetExhausted() *Response {
+	return &Response{
+		Type: ResponseTypeBudgetExhausted,
+		Value: fmt.Sprintf("session limits reached after %d steps and %d tokens (max steps %d, max tokens %d) without a final answer",
+			a.steps, a.usage.TotalTokens, a.maxSteps, a.maxTokens),
+	}
+}
+
+// TruncatedError is returned when a response is cut off at the completion
+// limit of a single call while the session budget still had room.
+type TruncatedError struct {
+	Limit int64
+}
+
+func (e *TruncatedError) Error() string {
+	return fmt.Sprintf("response was cut off at the completion limit of %d tokens", e.Limit)
+}
@@ -0,0 +1,125 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgent_Budget(t *testing.T) {
+	testCases := []struct {
+		name            string
+		maxTokens       int64
+		maxSteps        int
+		maxCompletion   int64
+		prompt          string
+		usedTokens      int64
+		steps           int
+		wantNearLimit   bool
+		wantExhausted   bool
+		wantCompletions int64
+	}{
+		{
+			name:            "no limits",
+			usedTokens:      1_000_000,
+			steps:           100,
+			wantCompletions: defaultCompletionTokens,
+		},
+		{
+			name:            "within budget",
+			maxTokens:       10_000,
+			maxSteps:        10,
+			usedTokens:      2_000,
+			steps:           3,
+			wantCompletions: defaultCompletionTokens,
+		},
+		{
+			name:            "token budget nearly used",
+			maxTokens:       10_000,
+			usedTokens:      9_000,
+			steps:           3,
+			wantNearLimit:   true,
+			wantCompletions: 1_000,
+		},
+		{
+			name:            "last step",
+			maxSteps:        4,
+			steps:           3,
+			wantNearLimit:   true,
+			wantCompletions: defaultCompletionTokens,
+		},
+		{
+			name:            "too little left for a final answer",
+			maxTokens:       10_000,
+			usedTokens:      9_700,
+			wantNearLimit:   true,
+			wantExhausted:   true,
+			wantCompletions: 300,
+		},
+		{
+			name:            "token budget exhausted",
+			maxTokens:       10_000,
+			usedTokens:      10_500,
+			wantNearLimit:   true,
+			wantExhausted:   true,
+			wantCompletions: 1,
+		},
+		{
+			name:            "prompt of the next call counts",
+			maxTokens:       10_000,
+			usedTokens:      6_000,
+			prompt:          strings.Repeat("abcd", 2_000),
+			wantCompletions: 1_787,
+		},
+		{
+			name:            "prompt leaves too little for a final answer",
+			maxTokens:       10_000,
+			usedTokens:      7_500,
+			prompt:          strings.Repeat("abcd", 2_000),
+			wantExhausted:   true,
+			wantCompletions: 287,
+		},
+		{
+			name:            "configured completion cap",
+			maxCompletion:   16_000,
+			wantCompletions: 16_000,
+		},
+		{
+			name:            "configured completion cap within budget",
+			maxTokens:       10_000,
+			maxCompletion:   16_000,
+			usedTokens:      2_000,
+			wantCompletions: 8_000,
+		},
+		{
+			name:            "step limit reached",
+			maxSteps:        4,
+			steps:           4,
+			wantNearLimit:   true,
+			wantExhausted:   true,
+			wantCompletions: defaultCompletionTokens,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &Agent{
+				maxTokens:           tc.maxTokens,
+				maxSteps:            tc.maxSteps,
+				maxCompletionTokens: tc.maxCompletion,
+				steps:               tc.steps,
+				usage:               Usage{TotalTokens: tc.usedTokens},
+			}
+			if tc.prompt != "" {
+				a.history = []openai.ChatCompletionMessageParamUnion{openai.UserMessage(tc.prompt)}
+			}
+
+			assert.Equal(t, tc.wantNearLimit, a.nearLimit())
+			assert.Equal(t, tc.wantExhausted, a.exhausted())
+			assert.Equal(t, tc.wantCompletions, a.completionLimit())
+		})
+	}
+}
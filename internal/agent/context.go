@@ -0,0 +1,141 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/openai/openai-go"
+)
+
+const (
+	// tokenMarginPercent is added to every estimate, since the tokenizers of
+	// the model families differ and the estimate must not fall short.
+	tokenMarginPercent = 10
+	// maxToolResultTokens caps the size of a single tool result.
+	maxToolResultTokens = 8_000
+	// compactRatio is the share of the context window after which older tool
+	// results are elided from the history.
+	compactRatio = 0.75
+	elidedPrefix = "[elided"
+)
+
+var truncationHints = map[string]string{
+	"git_command": "Narrow the command down, e.g. with '--stat', a pathspec ('-- <path>') or fewer commits ('-n').",
+	"read_file":   "Use grep to find the relevant lines instead of reading the whole file.",
+	"list_files":  "List a subdirectory instead.",
+	"glob":        "Use a more specific pattern.",
+	"grep":        "Use a more specific pattern or limit the search to a path.",
+}
+
+// estimateTokens approximates the token count of s without the tokenizer of
+// the model, using a character class heuristic: ASCII letters and digits
+// count a quarter token each, other ASCII characters such as punctuation and
+// whitespace, which BPE tokenizers rarely merge in code, diffs and JSON, half
+// a token, and every non-ASCII rune a whole token. The sum is rounded up and
+// increased by tokenMarginPercent.
+func estimateTokens(s string) int {
+	quarters := 0
+	for _, r := range s {
+		switch {
+		case r >= utf8.RuneSelf:
+			quarters += 4
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+			quarters++
+		default:
+			quarters += 2
+		}
+	}
+
+	tokens := (quarters + 3) / 4
+
+	return tokens + (tokens*tokenMarginPercent+99)/100
+}
+
+// truncateToolResult cuts a tool result estimated at more than
+// maxToolResultTokens at a line boundary and appends a marker with a hint on
+// how to fetch more.
+func truncateToolResult(name, result string) string {
+	if estimateTokens(result) <= maxToolResultTokens {
+		return result
+	}
+
+	// The estimate of a prefix grows with its length, so the longest prefix
+	// within the limit is found by binary search.
+	n := sort.Search(len(result), func(n int) bool {
+		return estimateTokens(result[:runeStart(result, n)]) > maxToolResultTokens
+	})
+
+	cut := runeStart(result, n-1)
+	if i := strings.LastIndexByte(result[:cut], '\n'); i > cut/2 {
+		cut = i + 1
+	}
+
+	hint, ok := truncationHints[name]
+	if !ok {
+		hint = "Narrow the request to fetch the rest."
+	}
+
+	return fmt.Sprintf("%s\n[truncated %d bytes] %s", result[:cut], len(result)-cut, hint)
+}
+
+// runeStart moves n back to the start of the rune it points into.
+func runeStart(s string, n int) int {
+	for n > 0 && n < len(s) && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return n
+}
+
+func historyTokens(history []openai.ChatCompletionMessageParamUnion) int {
+	total := 0
+	for _, m := range history {
+		data, _ := json.Marshal(m)
+		total += estimateTokens(string(data))
+	}
+
+	return total
+}
+
+// compactHistory elides the oldest tool results once the history approaches
+// the context window. Results of the latest tool calls are kept.
+func (a *Agent) compactHistory() {
+	if a.contextWindow <= 0 {
+		return
+	}
+
+	limit := int(compactRatio * float64(a.contextWindow))
+
+	total := historyTokens(a.history)
+	if total <= limit {
+		return
+	}
+
+	latest := len(a.history)
+	for i := len(a.history) - 1; i >= 0; i-- {
+		if m := a.history[i].OfAssistant; m != nil && len(m.ToolCalls) > 0 {
+			latest = i
+			break
+		}
+	}
+
+	for i := 0; i < latest && total > limit; i++ {
+		m := a.history[i].OfTool
+		if m == nil {
+			continue
+		}
+
+		content := m.Content.OfString.Value
+		if strings.HasPrefix(content, elidedPrefix) {
+			continue
+		}
+
+		elided := fmt.Sprintf("%s %d bytes of earlier tool output to save context, call the tool again if needed]", elidedPrefix, len(content))
+		total -= estimateTokens(content) - estimateTokens(elided)
+
+		a.history[i] = openai.ToolMessage(elided, m.ToolCallID)
+	}
+}
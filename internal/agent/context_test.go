@@ -0,0 +1,159 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateToolResult(t *testing.T) {
+	line := strings.Repeat("a", 99) + "\n"
+	diffLine := "+\tif err := json.Unmarshal(data, &resp); err != nil {\n"
+
+	testCases := []struct {
+		name       string
+		tool       string
+		result     string
+		wantLen    int
+		wantMarker string
+	}{
+		{
+			name:    "short result unchanged",
+			tool:    "git_command",
+			result:  "M main.go\n",
+			wantLen: len("M main.go\n"),
+		},
+		{
+			name:       "cut at line boundary",
+			tool:       "git_command",
+			result:     strings.Repeat(line, 338),
+			wantLen:    28_800,
+			wantMarker: "[truncated 5000 bytes] Narrow the command down",
+		},
+		{
+			name:       "multibyte runes are not split",
+			tool:       "read_file",
+			result:     "x" + strings.Repeat("é", 10_000),
+			wantLen:    14_543,
+			wantMarker: "Use grep to find the relevant lines",
+		},
+		{
+			name:       "unknown tool uses generic hint",
+			tool:       "other",
+			result:     strings.Repeat("b", 29_098),
+			wantLen:    29_088,
+			wantMarker: "[truncated 10 bytes] Narrow the request",
+		},
+		{
+			name:       "diff",
+			tool:       "git_command",
+			result:     strings.Repeat(diffLine, 2_000),
+			wantLen:    20_628,
+			wantMarker: "Narrow the command down",
+		},
+		{
+			name:       "CJK text",
+			tool:       "read_file",
+			result:     strings.Repeat("変更をコミットする。\n", 2_000),
+			wantLen:    21_452,
+			wantMarker: "Use grep to find the relevant lines",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := truncateToolResult(tc.tool, tc.result)
+
+			if tc.wantMarker == "" {
+				assert.Equal(t, tc.result, got)
+				return
+			}
+
+			body, marker, ok := strings.Cut(got, "\n[truncated ")
+			assert.True(t, ok)
+			assert.Len(t, body, tc.wantLen)
+			assert.LessOrEqual(t, estimateTokens(body), maxToolResultTokens)
+			assert.Contains(t, "[truncated "+marker, tc.wantMarker)
+			assert.True(t, strings.HasPrefix(tc.result, body))
+		})
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  int
+	}{
+		{
+			name:  "empty",
+			input: "",
+			want:  0,
+		},
+		{
+			name:  "letters and digits",
+			input: strings.Repeat("ab12", 100),
+			want:  110,
+		},
+		{
+			name:  "punctuation and whitespace",
+			input: strings.Repeat("{ ", 100),
+			want:  110,
+		},
+		{
+			name:  "non-ASCII runes",
+			input: strings.Repeat("é", 100),
+			want:  110,
+		},
+		{
+			name:  "rounded up",
+			input: "a",
+			want:  2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, estimateTokens(tc.input))
+		})
+	}
+}
+
+func TestAgent_CompactHistory(t *testing.T) {
+	big := strings.Repeat("x", 4000)
+
+	toolCall := func(id string) openai.ChatCompletionMessageParamUnion {
+		return openai.ChatCompletionMessage{
+			Role:      "assistant",
+			ToolCalls: []openai.ChatCompletionMessageToolCall{{ID: id, Function: openai.ChatCompletionMessageToolCallFunction{Name: "read_file"}}},
+		}.ToParam()
+	}
+
+	history := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage("system"),
+		toolCall("1"),
+		openai.ToolMessage(big, "1"),
+		toolCall("2"),
+		openai.ToolMessage(big, "2"),
+		toolCall("3"),
+		openai.ToolMessage(big, "3"),
+	}
+
+	a := &Agent{history: history, contextWindow: 2000}
+	a.compactHistory()
+
+	contents := make([]string, 0, 3)
+	for _, m := range a.history {
+		if m.OfTool != nil {
+			contents = append(contents, m.OfTool.Content.OfString.Value)
+		}
+	}
+
+	assert.Equal(t, "[elided 4000 bytes of earlier tool output to save context, call the tool again if needed]", contents[0])
+	assert.Equal(t, "2", a.history[4].OfTool.ToolCallID)
+	assert.True(t, strings.HasPrefix(contents[1], elidedPrefix))
+	assert.Equal(t, big, contents[2], "latest tool results are kept")
+	assert.LessOrEqual(t, historyTokens(a.history), 1500)
+}
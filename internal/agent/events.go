@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/openai/openai-go"
+)
+
+// Event is an event of an agent session, see Subscribe.
+type Event interface {
+	// Kind is a short name of the event, e.g. "tool_end".
+	Kind() string
+}
+
+// Reasons of a RunStartEvent.
+const (
+	RunReasonGenerate      = "generate"
+	RunReasonRegenerate    = "regenerate"
+	RunReasonCommitFailure = "commit_failure"
+)
+
+// RunStartEvent is emitted when Run, Regenerate or ExplainCommitFailure
+// starts.
+type RunStartEvent struct {
+	Reason string
+}
+
+// LLMRequestEvent is emitted before every model call.
+type LLMRequestEvent struct {
+	// Step is the number of the call in the session, starting at 1.
+	Step int
+	// Explorer reports whether the call goes to Config.Explorer.
+	Explorer bool
+	Params   *openai.ChatCompletionNewParams
+}
+
+// LLMResponseEvent is emitted after every successful model call.
+type LLMResponseEvent struct {
+	Step     int
+	Explorer bool
+	Response *openai.ChatCompletion
+	Duration time.Duration
+}
+
+// ContentDeltaEvent carries streamed assistant text between tool calls.
+type ContentDeltaEvent struct {
+	Delta string
+}
+
+// MessageDeltaEvent carries a streamed part of the final commit message.
+type MessageDeltaEvent struct {
+	Delta string
+}
+
+// ToolStartEvent is emitted before a tool is called.
+type ToolStartEvent struct {
+	ToolCall *openai.ChatCompletionMessageToolCall
+}
+
+// ToolEndEvent is emitted after a tool call, with its output or error.
+type ToolEndEvent struct {
+	ToolCall *openai.ChatCompletionMessageToolCall
+	Result   *ToolResult
+}
+
+// ToolResult is the outcome of a single tool call.
+type ToolResult struct {
+	Output   string
+	Err      error
+	Duration time.Duration
+}
+
+// ValidationFailedEvent is emitted when a generated message is rejected and
+// the model is asked to fix it.
+type ValidationFailedEvent struct {
+	Violations []string
+}
+
+// RetryEvent is emitted when a failed model call is retried. It is not
+// emitted by the agent but by the caller wrapping the provider.
+type RetryEvent struct {
+	// Attempt is the number of the upcoming retry, starting at 1.
+	Attempt int
+	Delay   time.Duration
+	Err     error
+}
+
+// FallbackEvent is emitted when a model failed and the next one of a
+// fallback list is used. Like RetryEvent, it is emitted by the caller.
+type FallbackEvent struct {
+	Model string
+	Err   error
+}
+
+// FinalEvent is emitted with the response of a run.
+type FinalEvent struct {
+	Response *Response
+	// Model is the model that produced the response.
+	Model string
+	// Usage is the token usage of the session so far.
+	Usage Usage
+}
+
+// ErrorEvent is emitted when a run fails.
+type ErrorEvent struct {
+	Err error
+}
+
+func (*RunStartEvent) Kind() string         { return "run_start" }
+func (*LLMRequestEvent) Kind() string       { return "llm_request" }
+func (*LLMResponseEvent) Kind() string      { return "llm_response" }
+func (*ContentDeltaEvent) Kind() string     { return "content_delta" }
+func (*MessageDeltaEvent) Kind() string     { return "message_delta" }
+func (*ToolStartEvent) Kind() string        { return "tool_start" }
+func (*ToolEndEvent) Kind() string          { return "tool_end" }
+func (*ValidationFailedEvent) Kind() string { return "validation_failed" }
+func (*RetryEvent) Kind() string            { return "retry" }
+func (*FallbackEvent) Kind() string         { return "fallback" }
+func (*FinalEvent) Kind() string            { return "final" }
+func (*ErrorEvent) Kind() string            { return "error" }
@@ -2,72 +2,58 @@ package agent
 
 import (
 	"context"
-
-	"github.com/openai/openai-go"
+	"reflect"
+	"sync"
 )
 
-type onIntermediateStep func(ctx context.Context, response *openai.ChatCompletion)
-type onCallTool func(ctx context.Context, toolCall *openai.ChatCompletionMessageToolCall)
-
+// Hooks delivers the events of an agent session to the subscribed functions.
+// Events are delivered one at a time, even from tool calls running in
+// parallel, so subscribers don't need to synchronize. A subscriber must not
+// emit events itself.
+//
+// The zero value is ready to use.
 type Hooks struct {
-	onIntermidiateStep      []onIntermediateStep
-	onAfterIntermidiateStep []onIntermediateStep
-	onBeforeCallTool        []onCallTool
-	onAfterCallTool         []onCallTool
-}
-
-func (h *Hooks) AddOnIntermidiateStep(hook onIntermediateStep) {
-	h.onIntermidiateStep = append(h.onIntermidiateStep, hook)
-}
-
-func (h *Hooks) AddOnAfterIntermidiateStep(hook onIntermediateStep) {
-	h.onAfterIntermidiateStep = append(h.onAfterIntermidiateStep, hook)
-}
-
-func (h *Hooks) AddBeforeCallTool(hook onCallTool) {
-	h.onBeforeCallTool = append(h.onBeforeCallTool, hook)
+	mu          sync.Mutex
+	subscribers map[reflect.Type][]func(ctx context.Context, e Event)
+	all         []func(ctx context.Context, e Event)
 }
 
-func (h *Hooks) AddAfterCallTool(hook onCallTool) {
-	h.onAfterCallTool = append(h.onAfterCallTool, hook)
-}
+// Subscribe registers fn for all events of type E, e.g.
+//
+//	agent.Subscribe(hooks, func(ctx context.Context, e *agent.ToolEndEvent) { ... })
+func Subscribe[E Event](h *Hooks, fn func(ctx context.Context, e E)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
-func (h *Hooks) handleIntermidiateStep(ctx context.Context, response *openai.ChatCompletion) {
-	if len(h.onIntermidiateStep) == 0 {
-		return
+	if h.subscribers == nil {
+		h.subscribers = make(map[reflect.Type][]func(ctx context.Context, e Event))
 	}
 
-	for _, hook := range h.onIntermidiateStep {
-		hook(ctx, response)
-	}
+	t := reflect.TypeFor[E]()
+	h.subscribers[t] = append(h.subscribers[t], func(ctx context.Context, e Event) {
+		fn(ctx, e.(E))
+	})
 }
 
-func (h *Hooks) handleAfterIntermidiateStep(ctx context.Context, response *openai.ChatCompletion) {
-	if len(h.onAfterIntermidiateStep) == 0 {
-		return
-	}
+// SubscribeAll registers fn for every event, e.g. to log a session.
+func (h *Hooks) SubscribeAll(fn func(ctx context.Context, e Event)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
-	for _, hook := range h.onAfterIntermidiateStep {
-		hook(ctx, response)
-	}
+	h.all = append(h.all, fn)
 }
 
-func (h *Hooks) handleBeforeCallTool(ctx context.Context, toolCall *openai.ChatCompletionMessageToolCall) {
-	if len(h.onBeforeCallTool) == 0 {
-		return
-	}
-
-	for _, hook := range h.onBeforeCallTool {
-		hook(ctx, toolCall)
-	}
-}
+// Emit delivers e to its subscribers. The agent emits its own events, Emit is
+// exported for events from outside of it, such as retries of the provider.
+func (h *Hooks) Emit(ctx context.Context, e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
-func (h *Hooks) handleAfterCallTool(ctx context.Context, toolCall *openai.ChatCompletionMessageToolCall) {
-	if len(h.onAfterCallTool) == 0 {
-		return
+	for _, fn := range h.subscribers[reflect.TypeOf(e)] {
+		fn(ctx, e)
 	}
 
-	for _, hook := range h.onAfterCallTool {
-		hook(ctx, toolCall)
+	for _, fn := range h.all {
+		fn(ctx, e)
 	}
 }
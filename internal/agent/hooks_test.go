@@ -0,0 +1,40 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHooks_Subscribe(t *testing.T) {
+	var (
+		retries []int
+		errs    []error
+		kinds   []string
+	)
+
+	hooks := &Hooks{}
+	Subscribe(hooks, func(ctx context.Context, e *RetryEvent) {
+		retries = append(retries, e.Attempt)
+	})
+	Subscribe(hooks, func(ctx context.Context, e *ErrorEvent) {
+		errs = append(errs, e.Err)
+	})
+	hooks.SubscribeAll(func(ctx context.Context, e Event) {
+		kinds = append(kinds, e.Kind())
+	})
+
+	failure := errors.New("failure")
+
+	ctx := context.Background()
+	hooks.Emit(ctx, &RetryEvent{Attempt: 1})
+	hooks.Emit(ctx, &ContentDeltaEvent{Delta: "text"})
+	hooks.Emit(ctx, &RetryEvent{Attempt: 2})
+	hooks.Emit(ctx, &ErrorEvent{Err: failure})
+
+	assert.Equal(t, []int{1, 2}, retries)
+	assert.Equal(t, []error{failure}, errs)
+	assert.Equal(t, []string{"retry", "content_delta", "retry", "error"}, kinds)
+}
@@ -3,6 +3,7 @@ package agent
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"text/template"
 
 	_ "embed"
@@ -11,11 +12,18 @@ import (
 //go:embed system_prompt.md
 var systemPrompt string
 
-func buildSystemPrompt(instructions []string) (string, error) {
+func buildSystemPrompt(cfg *Config) (string, error) {
 	data := struct {
 		Instructions []string
+		Style        string
+		Candidates   int
 	}{
-		Instructions: instructions,
+		Instructions: cfg.Instructions,
+		Candidates:   cfg.Candidates,
+	}
+
+	if cfg.Style != nil {
+		data.Style = cfg.Style.Guidance
 	}
 
 	tmpl, err := template.New("improved_system_prompt").Parse(systemPrompt)
@@ -30,3 +38,38 @@ func buildSystemPrompt(instructions []string) (string, error) {
 
 	return buf.String(), nil
 }
+
+const wrapUpInstruction = "You are about to reach the token or step limit of this session. Stop exploring and " +
+	"respond now with your final answer based on the information you already gathered. Tools are no longer available."
+
+func repoContextMessage(rc *RepoContext) string {
+	var sb strings.Builder
+
+	sb.WriteString("The repository state was gathered up front.\n\n")
+	fmt.Fprintf(&sb, "## git status --porcelain\n```\n%s\n```\n\n", strings.TrimRight(rc.Status, "\n"))
+	fmt.Fprintf(&sb, "## git diff --staged\n```diff\n%s\n```\n\n", strings.TrimRight(truncateToolResult("git_command", rc.Diff), "\n"))
+
+	sb.WriteString("## Recent commit subjects\n")
+	if len(rc.Subjects) == 0 {
+		sb.WriteString("(no commits yet)\n")
+	}
+	for _, subject := range rc.Subjects {
+		sb.WriteString("- " + subject + "\n")
+	}
+
+	sb.WriteString("\nThis is usually enough to respond right away. Call tools only if you need more context, " +
+		"e.g. the contents of a changed file.")
+
+	return sb.String()
+}
+
+func regenerateFeedback(feedback string) string {
+	return fmt.Sprintf("The user asked to regenerate the commit message with this feedback: %s\n"+
+		"Use the information you already gathered and only call tools if the feedback requires it.", feedback)
+}
+
+func commitFailureFeedback(output string) string {
+	return fmt.Sprintf("Committing with the generated message failed. Output of git and its hooks:\n\n%s\n\n"+
+		"Explain why the commit was rejected and how the user can fix it. Respond with type %q; "+
+		"the user will retry with the same message after fixing and re-staging.", output, ResponseTypeSuggestion)
+}
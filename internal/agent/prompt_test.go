@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoContextMessage(t *testing.T) {
+	testCases := []struct {
+		name         string
+		rc           *RepoContext
+		wantContains []string
+	}{
+		{
+			name: "with history",
+			rc: &RepoContext{
+				Status:   "M  main.go\n",
+				Diff:     "diff --git a/main.go b/main.go\n+fmt.Println()\n",
+				Subjects: []string{"feat: add cli", "fix: handle empty diff"},
+			},
+			wantContains: []string{
+				"## git status --porcelain\n```\nM  main.go\n```",
+				"```diff\ndiff --git a/main.go b/main.go\n+fmt.Println()\n```",
+				"- feat: add cli\n- fix: handle empty diff\n",
+			},
+		},
+		{
+			name:         "empty repository",
+			rc:           &RepoContext{Status: "A  README.md\n", Diff: "+hello\n"},
+			wantContains: []string{"(no commits yet)"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := repoContextMessage(tc.rc)
+
+			for _, want := range tc.wantContains {
+				assert.Contains(t, got, want)
+			}
+		})
+	}
+}
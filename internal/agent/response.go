@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/haadi-coder/Git-Agent/internal/style"
 	"github.com/openai/openai-go"
 )
 
@@ -11,11 +12,23 @@ const (
 	ResponseTypeResult     = "result"
 	ResponseTypeSuggestion = "suggestion"
 	ResponseTypeError      = "error"
+	// ResponseTypeBudgetExhausted is returned by the agent itself when the
+	// session limits are reached before the model gave a final answer.
+	ResponseTypeBudgetExhausted = "budget_exhausted"
 )
 
 type Response struct {
-	Type  string `json:"type"`
-	Value string `json:"value"`
+	Type       string         `json:"type"`
+	Value      string         `json:"value"`
+	Commit     *style.Message `json:"commit,omitempty"`
+	Candidates []Candidate    `json:"candidates,omitempty"`
+}
+
+// Candidate is one of several alternative commit messages.
+type Candidate struct {
+	Value     string         `json:"value"`
+	Rationale string         `json:"rationale"`
+	Commit    *style.Message `json:"commit,omitempty"`
 }
 
 func parseResponse(content string) (*Response, error) {
@@ -28,28 +41,90 @@ func parseResponse(content string) (*Response, error) {
 	return resp, nil
 }
 
-var responseFormat = &openai.ChatCompletionNewParamsResponseFormatUnion{
-	OfJSONSchema: &openai.ResponseFormatJSONSchemaParam{
-		JSONSchema: openai.ResponseFormatJSONSchemaJSONSchemaParam{
-			Name:        "commit_response",
-			Description: openai.String("Response format for commit generation with error handling and suggestions"),
-			Strict:      openai.Bool(true),
-			Schema: &openai.FunctionParameters{
-				"type": "object",
-				"properties": map[string]any{
-					"type": map[string]any{
-						"type":        "string",
-						"enum":        []string{ResponseTypeError, ResponseTypeSuggestion, ResponseTypeResult},
-						"description": "The type of response: 'error', 'suggestion' or 'result'.",
-					},
-					"value": map[string]any{
-						"type":        "string",
-						"description": "The content of the response (error message, suggestion details or commit message).",
-					},
-				},
-				"required":             []string{"type", "value"},
+// render builds the final messages from their structured fields. Responses
+// without them keep the value written by the model.
+func (a *Agent) render(resp *Response) {
+	if resp.Commit != nil {
+		resp.Value = a.style.Format(resp.Commit)
+	}
+
+	for i := range resp.Candidates {
+		c := &resp.Candidates[i]
+		if c.Commit != nil {
+			c.Value = a.style.Format(c.Commit)
+		}
+	}
+
+	if len(resp.Candidates) > 0 {
+		resp.Value = resp.Candidates[0].Value
+	}
+}
+
+func newResponseFormat(preset *style.Preset, candidates int) *openai.ChatCompletionNewParamsResponseFormatUnion {
+	properties := map[string]any{
+		"type": map[string]any{
+			"type":        "string",
+			"enum":        []string{ResponseTypeError, ResponseTypeSuggestion, ResponseTypeResult},
+			"description": "The type of response: 'error', 'suggestion' or 'result'.",
+		},
+		"value": map[string]any{
+			"type":        "string",
+			"description": "The content of the response (error message, suggestion details or commit message).",
+		},
+	}
+	properties["commit"] = commitSchema(preset)
+	required := []string{"type", "value", "commit"}
+
+	if candidates > 1 {
+		candidateProperties := map[string]any{
+			"value": map[string]any{
+				"type":        "string",
+				"description": "The full commit message.",
+			},
+			"rationale": map[string]any{
+				"type":        "string",
+				"description": "One short sentence on how this alternative differs from the others.",
+			},
+		}
+		candidateProperties["commit"] = commitSchema(preset)
+		candidateRequired := []string{"value", "rationale", "commit"}
+
+		properties["candidates"] = map[string]any{
+			"type":        "array",
+			"description": fmt.Sprintf("%d alternative commit messages for 'result' responses, empty otherwise.", candidates),
+			"items": map[string]any{
+				"type":                 "object",
+				"properties":           candidateProperties,
+				"required":             candidateRequired,
 				"additionalProperties": false,
 			},
+		}
+		required = append(required, "candidates")
+	}
+
+	return &openai.ChatCompletionNewParamsResponseFormatUnion{
+		OfJSONSchema: &openai.ResponseFormatJSONSchemaParam{
+			JSONSchema: openai.ResponseFormatJSONSchemaJSONSchemaParam{
+				Name:        "commit_response",
+				Description: openai.String("Response format for commit generation with error handling and suggestions"),
+				Strict:      openai.Bool(true),
+				Schema: &openai.FunctionParameters{
+					"type":                 "object",
+					"properties":           properties,
+					"required":             required,
+					"additionalProperties": false,
+				},
+			},
+		},
+	}
+}
+
+func commitSchema(preset *style.Preset) map[string]any {
+	return map[string]any{
+		"description": "The fields of the commit message for 'result' responses, null otherwise.",
+		"anyOf": []any{
+			preset.Schema(),
+			map[string]any{"type": "null"},
 		},
-	},
+	}
 }
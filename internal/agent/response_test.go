@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/haadi-coder/Git-Agent/internal/llm"
+	"github.com/haadi-coder/Git-Agent/internal/style"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent_render(t *testing.T) {
+	conventional, err := style.Get(style.Conventional)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		cfg       *Config
+		resp      *Response
+		wantValue string
+	}{
+		{
+			name: "inferred style",
+			cfg:  &Config{},
+			resp: &Response{
+				Type:   ResponseTypeResult,
+				Value:  "Add refund endpoint",
+				Commit: &style.Message{Header: "Add refund endpoint", Body: []string{"Adds POST /refunds."}},
+			},
+			wantValue: "Add refund endpoint\n\nAdds POST /refunds.",
+		},
+		{
+			name: "preset",
+			cfg:  &Config{Style: conventional},
+			resp: &Response{
+				Type:   ResponseTypeResult,
+				Value:  "feat: add refund endpoint",
+				Commit: &style.Message{Type: "feat", Subject: "add refund endpoint", Breaking: "drops /v1"},
+			},
+			wantValue: "feat!: add refund endpoint\n\nBREAKING CHANGE: drops /v1",
+		},
+		{
+			name:      "without fields",
+			cfg:       &Config{},
+			resp:      &Response{Type: ResponseTypeSuggestion, Value: "Split the change."},
+			wantValue: "Split the change.",
+		},
+		{
+			name: "candidates",
+			cfg:  &Config{Candidates: 2},
+			resp: &Response{
+				Type: ResponseTypeResult,
+				Candidates: []Candidate{
+					{Value: "a", Commit: &style.Message{Header: "Add refunds"}},
+					{Value: "b", Commit: &style.Message{Header: "Support refunds"}},
+				},
+			},
+			wantValue: "Add refunds",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			replayer, err := llm.NewReplayer("testdata/run.jsonl", llm.MatchSequence, 128_000)
+			require.NoError(t, err)
+
+			a, err := NewAgent(replayer, tc.cfg, &Hooks{})
+			require.NoError(t, err)
+
+			a.render(tc.resp)
+
+			assert.Equal(t, tc.wantValue, tc.resp.Value)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package agent
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var valueKeyRgx = regexp.MustCompile(`"value"\s*:\s*"`)
+
+// deltaRouter forwards streamed content to the hooks. Free-form text is sent
+// as content deltas, while for a JSON response only the decoded "value"
+// field is sent as message deltas, so the commit message can be shown while
+// it is being written.
+type deltaRouter struct {
+	ctx   context.Context
+	hooks *Hooks
+	// messages is false when the response is not going to be used, so that
+	// a message is not shown.
+	messages bool
+
+	buf     strings.Builder
+	isJSON  bool
+	decided bool
+	// pos is the offset in buf up to which the value has been decoded, or -1
+	// while the value has not been found yet.
+	pos  int
+	done bool
+}
+
+func newDeltaRouter(ctx context.Context, hooks *Hooks, messages bool) *deltaRouter {
+	return &deltaRouter{ctx: ctx, hooks: hooks, messages: messages, pos: -1}
+}
+
+func (r *deltaRouter) write(delta string) {
+	if !r.decided {
+		trimmed := strings.TrimLeft(r.buf.String()+delta, " \t\r\n")
+		if trimmed == "" {
+			r.buf.WriteString(delta)
+			return
+		}
+
+		r.decided = true
+		r.isJSON = strings.HasPrefix(trimmed, "{")
+
+		if !r.isJSON {
+			delta = trimmed
+		}
+	}
+
+	if !r.isJSON {
+		r.hooks.Emit(r.ctx, &ContentDeltaEvent{Delta: delta})
+		return
+	}
+
+	if !r.messages {
+		return
+	}
+
+	r.buf.WriteString(delta)
+	if text := r.decodeValue(); text != "" {
+		r.hooks.Emit(r.ctx, &MessageDeltaEvent{Delta: text})
+	}
+}
+
+// decodeValue returns the part of the "value" string that has arrived since
+// the previous call.
+func (r *deltaRouter) decodeValue() string {
+	if r.done {
+		return ""
+	}
+
+	s := r.buf.String()
+
+	if r.pos < 0 {
+		loc := valueKeyRgx.FindStringIndex(s)
+		if loc == nil {
+			return ""
+		}
+		r.pos = loc[1]
+	}
+
+	var out strings.Builder
+
+	for r.pos < len(s) {
+		c := s[r.pos]
+
+		if c == '"' {
+			r.done = true
+			break
+		}
+
+		if c != '\\' {
+			_, size := utf8.DecodeRuneInString(s[r.pos:])
+			out.WriteString(s[r.pos : r.pos+size])
+			r.pos += size
+			continue
+		}
+
+		// Wait for the rest of an escape sequence split across deltas.
+		if r.pos+1 >= len(s) {
+			break
+		}
+
+		if s[r.pos+1] == 'u' {
+			if r.pos+6 > len(s) {
+				break
+			}
+
+			decoded, err := strconv.Unquote(`"` + s[r.pos:r.pos+6] + `"`)
+			if err == nil {
+				out.WriteString(decoded)
+			}
+			r.pos += 6
+			continue
+		}
+
+		decoded, err := strconv.Unquote(`"` + s[r.pos:r.pos+2] + `"`)
+		if err == nil {
+			out.WriteString(decoded)
+		}
+		r.pos += 2
+	}
+
+	return out.String()
+}
@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeltaRouter(t *testing.T) {
+	testCases := []struct {
+		name        string
+		deltas      []string
+		wantContent string
+		wantMessage string
+	}{
+		{
+			name:        "free-form text",
+			deltas:      []string{"\n", "Let me check ", "the diff"},
+			wantContent: "Let me check the diff",
+		},
+		{
+			name:        "json value",
+			deltas:      []string{`{"type":"res`, `ult","val`, `ue":"feat: add`, ` refunds\n\nBody`, ` \"quoted\""}`},
+			wantMessage: "feat: add refunds\n\nBody \"quoted\"",
+		},
+		{
+			name:        "escape split across deltas",
+			deltas:      []string{`{"value":"a\`, `nb \u00`, `e9`, `"}`},
+			wantMessage: "a\nb é",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var content, message strings.Builder
+
+			hooks := &Hooks{}
+			Subscribe(hooks, func(ctx context.Context, e *ContentDeltaEvent) { content.WriteString(e.Delta) })
+			Subscribe(hooks, func(ctx context.Context, e *MessageDeltaEvent) { message.WriteString(e.Delta) })
+
+			router := newDeltaRouter(context.Background(), hooks, true)
+			for _, d := range tc.deltas {
+				router.write(d)
+			}
+
+			assert.Equal(t, tc.wantContent, content.String())
+			assert.Equal(t, tc.wantMessage, message.String())
+		})
+	}
+}
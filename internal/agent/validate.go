@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+)
+
+const maxValidationAttempts = 3
+
+// Validator checks a generated commit message and returns the problems found
+// in it. An empty result means the message is accepted.
+type Validator func(message string) []string
+
+func (a *Agent) validate(message string) []string {
+	var violations []string
+
+	for _, validator := range a.validators {
+		violations = append(violations, validator(message)...)
+	}
+
+	return violations
+}
+
+// Validate reports the problems of a result response found by the
+// validators of the agent, e.g. for a response restored from a cache.
+func (a *Agent) Validate(resp *Response) []string {
+	if resp.Type != ResponseTypeResult {
+		return nil
+	}
+
+	return a.validateResponse(resp)
+}
+
+func (a *Agent) validateResponse(resp *Response) []string {
+	if len(resp.Candidates) == 0 {
+		return a.validate(resp.Value)
+	}
+
+	var violations []string
+	for i, c := range resp.Candidates {
+		for _, v := range a.validate(c.Value) {
+			violations = append(violations, fmt.Sprintf("candidate %d: %s", i+1, v))
+		}
+	}
+
+	return violations
+}
+
+func validationFeedback(violations []string) string {
+	var sb strings.Builder
+
+	sb.WriteString("The generated commit message was rejected for the following reasons:\n")
+	for _, v := range violations {
+		fmt.Fprintf(&sb, "- %s\n", v)
+	}
+	sb.WriteString("Fix these problems and respond with the corrected commit message.")
+
+	return sb.String()
+}
@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgent_Validate(t *testing.T) {
+	a := &Agent{validators: []Validator{
+		func(message string) []string {
+			if message != "PAY-1234 add refund flow" {
+				return []string{"subject line must start with ticket ID PAY-1234"}
+			}
+			return nil
+		},
+	}}
+
+	testCases := []struct {
+		name string
+		resp *Response
+		want []string
+	}{
+		{
+			name: "valid result",
+			resp: &Response{Type: ResponseTypeResult, Value: "PAY-1234 add refund flow"},
+		},
+		{
+			name: "invalid result",
+			resp: &Response{Type: ResponseTypeResult, Value: "PAY-999 add refund flow"},
+			want: []string{"subject line must start with ticket ID PAY-1234"},
+		},
+		{
+			name: "invalid candidate",
+			resp: &Response{
+				Type:       ResponseTypeResult,
+				Value:      "PAY-1234 add refund flow",
+				Candidates: []Candidate{{Value: "PAY-1234 add refund flow"}, {Value: "add refund flow"}},
+			},
+			want: []string{"candidate 2: subject line must start with ticket ID PAY-1234"},
+		},
+		{
+			name: "suggestion is not validated",
+			resp: &Response{Type: ResponseTypeSuggestion, Value: "Split the change."},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, a.Validate(tc.resp))
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Cache stores values in files named by their key. Entries older than MaxAge
+// are evicted, and the oldest ones once the total size exceeds MaxSize.
+type Cache struct {
+	Dir     string
+	MaxAge  time.Duration
+	MaxSize int64
+}
+
+// Key derives a cache key from the given parts.
+func Key(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		// Length prefixes keep ("ab", "c") and ("a", "bc") apart.
+		fmt.Fprintf(h, "%d:%s", len(part), part)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the value stored under key. A missing or expired entry is
+// reported as not found.
+func (c *Cache) Get(key string) ([]byte, bool, error) {
+	path := filepath.Join(c.Dir, key)
+
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to stat cache entry: %w", err)
+	}
+
+	if c.MaxAge > 0 && time.Since(info.ModTime()) > c.MaxAge {
+		return nil, false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	return data, true, nil
+}
+
+// Put stores value under key and evicts old entries.
+func (c *Cache) Put(key string, value []byte) error {
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(c.Dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(value); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(c.Dir, key)); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	return c.evict()
+}
+
+func (c *Cache) evict() error {
+	entries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	var (
+		files []fs.FileInfo
+		total int64
+	)
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		if c.MaxAge > 0 && time.Since(info.ModTime()) > c.MaxAge {
+			_ = os.Remove(filepath.Join(c.Dir, info.Name()))
+			continue
+		}
+
+		files = append(files, info)
+		total += info.Size()
+	}
+
+	if c.MaxSize <= 0 {
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+
+	for _, info := range files {
+		if total <= c.MaxSize {
+			break
+		}
+
+		_ = os.Remove(filepath.Join(c.Dir, info.Name()))
+		total -= info.Size()
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, Key("a", "b"), Key("a", "b"))
+	assert.NotEqual(t, Key("ab", "c"), Key("a", "bc"))
+	assert.Len(t, Key(), 64)
+}
+
+func TestCache_GetPut(t *testing.T) {
+	c := &Cache{Dir: filepath.Join(t.TempDir(), "cache"), MaxAge: time.Hour}
+
+	_, ok, err := c.Get("missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, c.Put("key", []byte("value")))
+
+	got, ok, err := c.Get("key")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("value"), got)
+
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(c.Dir, "key"), old, old))
+
+	_, ok, err = c.Get("key")
+	require.NoError(t, err)
+	assert.False(t, ok, "expired entries are not returned")
+}
+
+func TestCache_Evict(t *testing.T) {
+	testCases := []struct {
+		name     string
+		maxAge   time.Duration
+		maxSize  int64
+		ages     map[string]time.Duration
+		wantKept []string
+	}{
+		{
+			name:     "by age",
+			maxAge:   time.Hour,
+			ages:     map[string]time.Duration{"old": 2 * time.Hour, "new": time.Minute},
+			wantKept: []string{"last", "new"},
+		},
+		{
+			name:     "by size keeps the newest",
+			maxSize:  10,
+			ages:     map[string]time.Duration{"a": 3 * time.Minute, "b": 2 * time.Minute, "c": time.Minute},
+			wantKept: []string{"c", "last"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Cache{Dir: t.TempDir(), MaxAge: tc.maxAge, MaxSize: tc.maxSize}
+
+			for key, age := range tc.ages {
+				path := filepath.Join(c.Dir, key)
+				require.NoError(t, os.WriteFile(path, []byte("12345"), 0o644))
+
+				mtime := time.Now().Add(-age)
+				require.NoError(t, os.Chtimes(path, mtime, mtime))
+			}
+
+			require.NoError(t, c.Put("last", []byte("12345")))
+
+			entries, err := os.ReadDir(c.Dir)
+			require.NoError(t, err)
+
+			var kept []string
+			for _, e := range entries {
+				kept = append(kept, e.Name())
+			}
+			assert.Equal(t, tc.wantKept, kept)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package commitlint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	LevelDisabled = 0
+	LevelWarning  = 1
+	LevelError    = 2
+)
+
+const conventionalPreset = "@commitlint/config-conventional"
+
+// configFiles lists the supported commitlint configuration files in the order
+// they are looked up.
+var configFiles = []string{
+	".commitlintrc.json",
+	".commitlintrc.yaml",
+	".commitlintrc.yml",
+	".commitlintrc",
+}
+
+type Rule struct {
+	Level      int
+	Applicable string
+	Value      any
+}
+
+type Config struct {
+	Path  string
+	Rules map[string]Rule
+}
+
+type rawConfig struct {
+	Extends any              `json:"extends" yaml:"extends"`
+	Rules   map[string][]any `json:"rules" yaml:"rules"`
+}
+
+// Load looks for a commitlint configuration file in dir. A nil config is
+// returned when no configuration file exists.
+func Load(dir string) (*Config, error) {
+	for _, name := range configFiles {
+		path := filepath.Join(dir, name)
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to read commitlint config: %w", err)
+		}
+
+		cfg, err := parse(data, filepath.Ext(name) == ".json")
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		cfg.Path = path
+
+		return cfg, nil
+	}
+
+	return nil, nil
+}
+
+func parse(data []byte, isJSON bool) (*Config, error) {
+	var raw rawConfig
+
+	var err error
+	if isJSON {
+		err = json.Unmarshal(data, &raw)
+	} else {
+		err = yaml.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{Rules: make(map[string]Rule)}
+
+	if extendsConventional(raw.Extends) {
+		for name, rule := range conventionalRules {
+			cfg.Rules[name] = rule
+		}
+	}
+
+	for name, values := range raw.Rules {
+		rule, err := parseRule(values)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", name, err)
+		}
+
+		cfg.Rules[name] = rule
+	}
+
+	return cfg, nil
+}
+
+func parseRule(values []any) (Rule, error) {
+	if len(values) == 0 {
+		return Rule{}, fmt.Errorf("empty rule")
+	}
+
+	level, ok := toInt(values[0])
+	if !ok {
+		return Rule{}, fmt.Errorf("invalid level %v", values[0])
+	}
+
+	rule := Rule{Level: level, Applicable: "always"}
+
+	if len(values) > 1 {
+		applicable, ok := values[1].(string)
+		if !ok || (applicable != "always" && applicable != "never") {
+			return Rule{}, fmt.Errorf("invalid applicability %v", values[1])
+		}
+		rule.Applicable = applicable
+	}
+
+	if len(values) > 2 {
+		rule.Value = values[2]
+	}
+
+	return rule, nil
+}
+
+func extendsConventional(extends any) bool {
+	switch v := extends.(type) {
+	case string:
+		return v == conventionalPreset
+	case []any:
+		return slices.ContainsFunc(v, func(e any) bool {
+			s, ok := e.(string)
+			return ok && s == conventionalPreset
+		})
+	}
+
+	return false
+}
+
+func toInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case float64:
+		return int(n), true
+	}
+
+	return 0, false
+}
+
+func toStrings(v any) []string {
+	switch values := v.(type) {
+	case []string:
+		return values
+	case string:
+		return []string{values}
+	case []any:
+		var result []string
+		for _, e := range values {
+			if s, ok := e.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+
+	return nil
+}
+
+// conventionalRules mirrors the subset of @commitlint/config-conventional
+// that is understood by this package.
+var conventionalRules = map[string]Rule{
+	RuleTypeEnum: {
+		Level:      LevelError,
+		Applicable: "always",
+		Value:      []string{"build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test"},
+	},
+	RuleSubjectCase: {
+		Level:      LevelError,
+		Applicable: "never",
+		Value:      []string{"sentence-case", "start-case", "pascal-case", "upper-case"},
+	},
+	RuleHeaderMaxLength: {
+		Level:      LevelError,
+		Applicable: "always",
+		Value:      100,
+	},
+}
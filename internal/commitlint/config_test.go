@@ -0,0 +1,80 @@
+package commitlint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name      string
+		file      string
+		content   string
+		wantRules map[string]Rule
+		wantErr   bool
+	}{
+		{
+			name:    "json config",
+			file:    ".commitlintrc.json",
+			content: `{"rules": {"type-enum": [2, "always", ["feat", "fix"]], "header-max-length": [1, "always", 72]}}`,
+			wantRules: map[string]Rule{
+				RuleTypeEnum:        {Level: LevelError, Applicable: "always", Value: []any{"feat", "fix"}},
+				RuleHeaderMaxLength: {Level: LevelWarning, Applicable: "always", Value: float64(72)},
+			},
+		},
+		{
+			name:    "yaml config",
+			file:    ".commitlintrc.yaml",
+			content: "rules:\n  scope-enum: [2, always, [api, cli]]\n  subject-case: [0]\n",
+			wantRules: map[string]Rule{
+				RuleScopeEnum:   {Level: LevelError, Applicable: "always", Value: []any{"api", "cli"}},
+				RuleSubjectCase: {Level: LevelDisabled, Applicable: "always"},
+			},
+		},
+		{
+			name:    "extends conventional",
+			file:    ".commitlintrc.yml",
+			content: "extends: ['@commitlint/config-conventional']\nrules:\n  header-max-length: [2, always, 72]\n",
+			wantRules: map[string]Rule{
+				RuleTypeEnum:        conventionalRules[RuleTypeEnum],
+				RuleSubjectCase:     conventionalRules[RuleSubjectCase],
+				RuleHeaderMaxLength: {Level: LevelError, Applicable: "always", Value: 72},
+			},
+		},
+		{
+			name:    "invalid applicability",
+			file:    ".commitlintrc.json",
+			content: `{"rules": {"type-enum": [2, "sometimes", ["feat"]]}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, tc.file), []byte(tc.content), 0644))
+
+			cfg, err := Load(dir)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, cfg)
+			assert.Equal(t, filepath.Join(dir, tc.file), cfg.Path)
+			assert.Equal(t, tc.wantRules, cfg.Rules)
+		})
+	}
+
+	t.Run("no config", func(t *testing.T) {
+		cfg, err := Load(t.TempDir())
+		require.NoError(t, err)
+		assert.Nil(t, cfg)
+	})
+}
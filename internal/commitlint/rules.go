@@ -0,0 +1,210 @@
+package commitlint
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	RuleTypeEnum        = "type-enum"
+	RuleScopeEnum       = "scope-enum"
+	RuleSubjectCase     = "subject-case"
+	RuleHeaderMaxLength = "header-max-length"
+)
+
+var headerRgx = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?!?: (.*)$`)
+
+type header struct {
+	raw     string
+	typ     string
+	scopes  []string
+	subject string
+}
+
+func parseHeader(message string) header {
+	raw, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	raw = strings.TrimSpace(raw)
+
+	h := header{raw: raw, subject: raw}
+
+	match := headerRgx.FindStringSubmatch(raw)
+	if match == nil {
+		return h
+	}
+
+	h.typ = match[1]
+	h.subject = match[3]
+
+	for _, scope := range strings.Split(match[2], ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			h.scopes = append(h.scopes, scope)
+		}
+	}
+
+	return h
+}
+
+// Validate checks message against the error level rules of the config and
+// returns a description of every violation.
+func (c *Config) Validate(message string) []string {
+	h := parseHeader(message)
+
+	var violations []string
+	for _, name := range c.ruleNames() {
+		rule := c.Rules[name]
+		if rule.Level < LevelError {
+			continue
+		}
+
+		if v := checkRule(name, rule, h); v != "" {
+			violations = append(violations, fmt.Sprintf("%s (commitlint %s)", v, name))
+		}
+	}
+
+	return violations
+}
+
+// Instructions translates the enabled rules into guidance for the agent.
+func (c *Config) Instructions() []string {
+	var instructions []string
+
+	for _, name := range c.ruleNames() {
+		rule := c.Rules[name]
+		if rule.Level == LevelDisabled {
+			continue
+		}
+
+		if i := describeRule(name, rule); i != "" {
+			instructions = append(instructions, i)
+		}
+	}
+
+	return instructions
+}
+
+func (c *Config) ruleNames() []string {
+	names := make([]string, 0, len(c.Rules))
+	for name := range c.Rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func checkRule(name string, rule Rule, h header) string {
+	always := rule.Applicable == "always"
+
+	switch name {
+	case RuleTypeEnum:
+		allowed := toStrings(rule.Value)
+		if h.typ == "" {
+			if always {
+				return "header must have the form 'type(scope): subject'"
+			}
+			return ""
+		}
+
+		if slices.Contains(allowed, h.typ) != always {
+			return fmt.Sprintf("type %q is not allowed, %s", h.typ, enumHint(always, allowed))
+		}
+
+	case RuleScopeEnum:
+		allowed := toStrings(rule.Value)
+		for _, scope := range h.scopes {
+			if slices.Contains(allowed, scope) != always {
+				return fmt.Sprintf("scope %q is not allowed, %s", scope, enumHint(always, allowed))
+			}
+		}
+
+	case RuleSubjectCase:
+		cases := toStrings(rule.Value)
+		if h.subject == "" {
+			return ""
+		}
+
+		matches := slices.ContainsFunc(cases, func(c string) bool {
+			return matchesCase(h.subject, c)
+		})
+		if matches != always {
+			if always {
+				return fmt.Sprintf("subject must be %s", strings.Join(cases, " or "))
+			}
+			return fmt.Sprintf("subject must not be %s", strings.Join(cases, ", "))
+		}
+
+	case RuleHeaderMaxLength:
+		limit, ok := toInt(rule.Value)
+		if ok && utf8.RuneCountInString(h.raw) > limit {
+			return fmt.Sprintf("header must not be longer than %d characters, current length is %d", limit, utf8.RuneCountInString(h.raw))
+		}
+	}
+
+	return ""
+}
+
+func describeRule(name string, rule Rule) string {
+	always := rule.Applicable == "always"
+
+	switch name {
+	case RuleTypeEnum:
+		return fmt.Sprintf("Commit type %s", enumHint(always, toStrings(rule.Value)))
+	case RuleScopeEnum:
+		return fmt.Sprintf("Commit scope, if any, %s", enumHint(always, toStrings(rule.Value)))
+	case RuleSubjectCase:
+		if always {
+			return fmt.Sprintf("Subject must be %s", strings.Join(toStrings(rule.Value), " or "))
+		}
+		return fmt.Sprintf("Subject must not be %s", strings.Join(toStrings(rule.Value), ", "))
+	case RuleHeaderMaxLength:
+		if limit, ok := toInt(rule.Value); ok {
+			return fmt.Sprintf("Header (first line) must not be longer than %d characters", limit)
+		}
+	}
+
+	return ""
+}
+
+func enumHint(always bool, values []string) string {
+	if always {
+		return "must be one of: " + strings.Join(values, ", ")
+	}
+
+	return "must not be one of: " + strings.Join(values, ", ")
+}
+
+func matchesCase(s, target string) bool {
+	first, _ := utf8.DecodeRuneInString(s)
+
+	switch target {
+	case "lower-case", "lowercase":
+		return s == strings.ToLower(s)
+	case "upper-case", "uppercase":
+		return s == strings.ToUpper(s)
+	case "sentence-case", "sentencecase":
+		return unicode.IsUpper(first)
+	case "start-case", "startcase":
+		for _, word := range strings.Fields(s) {
+			r, _ := utf8.DecodeRuneInString(word)
+			if unicode.IsLetter(r) && !unicode.IsUpper(r) {
+				return false
+			}
+		}
+		return true
+	case "pascal-case", "pascalcase":
+		return unicode.IsUpper(first) && !strings.ContainsAny(s, " -_")
+	case "camel-case", "camelcase":
+		return unicode.IsLower(first) && !strings.ContainsAny(s, " -_")
+	case "kebab-case", "kebabcase":
+		return s == strings.ToLower(s) && !strings.ContainsAny(s, " _")
+	case "snake-case", "snakecase":
+		return s == strings.ToLower(s) && !strings.ContainsAny(s, " -")
+	}
+
+	return false
+}
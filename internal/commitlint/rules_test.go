@@ -0,0 +1,80 @@
+package commitlint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := &Config{
+		Rules: map[string]Rule{
+			RuleTypeEnum:        {Level: LevelError, Applicable: "always", Value: []any{"feat", "fix"}},
+			RuleScopeEnum:       {Level: LevelError, Applicable: "always", Value: []any{"api", "cli"}},
+			RuleSubjectCase:     {Level: LevelError, Applicable: "never", Value: []any{"sentence-case", "upper-case"}},
+			RuleHeaderMaxLength: {Level: LevelError, Applicable: "always", Value: 40},
+		},
+	}
+
+	testCases := []struct {
+		name      string
+		message   string
+		wantRules []string
+	}{
+		{
+			name:    "valid message",
+			message: "feat(api): add refund endpoint\n\nBody text.",
+		},
+		{
+			name:    "valid message without scope",
+			message: "fix: handle empty diff",
+		},
+		{
+			name:      "type not allowed",
+			message:   "chore: bump deps",
+			wantRules: []string{RuleTypeEnum},
+		},
+		{
+			name:      "not conventional header",
+			message:   "Add refund endpoint",
+			wantRules: []string{RuleSubjectCase, RuleTypeEnum},
+		},
+		{
+			name:      "scope not allowed",
+			message:   "feat(web): add page",
+			wantRules: []string{RuleScopeEnum},
+		},
+		{
+			name:      "sentence case subject",
+			message:   "feat: Add refund endpoint",
+			wantRules: []string{RuleSubjectCase},
+		},
+		{
+			name:      "header too long",
+			message:   "feat(api): add a refund endpoint with many options",
+			wantRules: []string{RuleHeaderMaxLength},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			violations := cfg.Validate(tc.message)
+
+			assert.Len(t, violations, len(tc.wantRules))
+			for i, rule := range tc.wantRules {
+				assert.Contains(t, violations[i], rule)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateIgnoresWarnings(t *testing.T) {
+	cfg := &Config{
+		Rules: map[string]Rule{
+			RuleHeaderMaxLength: {Level: LevelWarning, Applicable: "always", Value: 10},
+		},
+	}
+
+	assert.Empty(t, cfg.Validate("feat: a header longer than ten characters"))
+	assert.NotEmpty(t, cfg.Instructions())
+}
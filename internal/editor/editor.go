@@ -0,0 +1,96 @@
+package editor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/haadi-coder/Git-Agent/internal/git"
+)
+
+const commentHeader = `Please edit the commit message for your changes. Lines starting
+with '#' will be ignored, and an empty message aborts the commit.`
+
+// Edit opens message in the editor git would use (GIT_EDITOR, core.editor,
+// VISUAL, EDITOR) and returns the edited text with comments stripped. The
+// details are appended to the file as '#' comments for reference. The editor
+// writes its screen to stdout, usually the terminal.
+func Edit(ctx context.Context, message, details string, stdout io.Writer) (string, error) {
+	editor, err := git.Run(ctx, "var", "GIT_EDITOR")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve editor: %w", err)
+	}
+
+	gitPath, err := git.Run(ctx, "rev-parse", "--git-path", "COMMIT_EDITMSG")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve message file: %w", err)
+	}
+	path := strings.TrimSpace(gitPath)
+
+	content := message + "\n\n" + comment(commentHeader+"\n\n"+details)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write message file: %w", err)
+	}
+
+	// git runs the editor through the shell so that values like
+	// "code --wait" work.
+	cmd := exec.CommandContext(ctx, "sh", "-c", strings.TrimSpace(editor)+` "$@"`, "editor", path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor failed: %w", err)
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read message file: %w", err)
+	}
+
+	return Cleanup(string(edited)), nil
+}
+
+// Cleanup strips comment lines, trailing whitespace and surplus blank lines
+// the same way git commit --cleanup=strip does.
+func Cleanup(text string) string {
+	var lines []string
+	blank := false
+
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func comment(text string) string {
+	var sb strings.Builder
+
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if line == "" {
+			sb.WriteString("#\n")
+		} else {
+			sb.WriteString("# " + line + "\n")
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,46 @@
+package editor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCleanup(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "strips comments",
+			input: "feat: add refunds\n\n# Please edit\n#\n# file.go | 2 +-\n",
+			want:  "feat: add refunds",
+		},
+		{
+			name:  "collapses blank lines and trims whitespace",
+			input: "\n\nfeat: add refunds  \n\n\n\nBody line\t\n\n",
+			want:  "feat: add refunds\n\nBody line",
+		},
+		{
+			name:  "comments between paragraphs",
+			input: "feat: add refunds\n# note\n\nBody line",
+			want:  "feat: add refunds\n\nBody line",
+		},
+		{
+			name:  "only comments",
+			input: "# Please edit\n#\n",
+			want:  "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Cleanup(tc.input))
+		})
+	}
+}
+
+func TestComment(t *testing.T) {
+	assert.Equal(t, "# first\n#\n# second\n", comment("first\n\nsecond\n"))
+}
@@ -0,0 +1,100 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+func Run(ctx context.Context, args ...string) (string, error) {
+	return RunInput(ctx, nil, args...)
+}
+
+func RunInput(ctx context.Context, stdin io.Reader, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stdin = stdin
+
+	stderr := bytes.Buffer{}
+	stdout := bytes.Buffer{}
+	cmd.Stderr = &stderr
+	cmd.Stdout = &stdout
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+
+	return stdout.String(), nil
+}
+
+func CurrentBranch(ctx context.Context) (string, error) {
+	out, err := Run(ctx, "branch", "--show-current")
+	if err != nil {
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	return strings.TrimSpace(out), nil
+}
+
+// TopLevel returns the absolute path of the root of the working tree.
+func TopLevel(ctx context.Context) (string, error) {
+	out, err := Run(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("failed to find repository root: %w", err)
+	}
+
+	return strings.TrimSpace(out), nil
+}
+
+func Config(ctx context.Context, key string) (string, error) {
+	out, err := Run(ctx, "config", "--get", key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read git config %s: %w", key, err)
+	}
+
+	return strings.TrimSpace(out), nil
+}
+
+// RecentSubjects returns the subject lines of the last n non-merge commits,
+// most recent first. A repository without commits yields no subjects.
+func RecentSubjects(ctx context.Context, n int) ([]string, error) {
+	if _, err := Run(ctx, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		return nil, nil
+	}
+
+	out, err := Run(ctx, "log", "--no-merges", fmt.Sprintf("-n%d", n), "--format=%s")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit history: %w", err)
+	}
+
+	var subjects []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			subjects = append(subjects, line)
+		}
+	}
+
+	return subjects, nil
+}
+
+// HasStagedChanges reports whether the index differs from HEAD.
+func HasStagedChanges(ctx context.Context) (bool, error) {
+	_, err := Run(ctx, "diff", "--cached", "--quiet")
+	if err == nil {
+		return false, nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+
+	return false, fmt.Errorf("failed to check staged changes: %w", err)
+}
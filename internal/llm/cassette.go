@@ -0,0 +1,255 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/openai/openai-go"
+)
+
+// Rules for matching a request to a recorded interaction during replay.
+const (
+	// MatchSequence serves the recorded responses in order, whatever the
+	// request. It reproduces sessions recorded in another repository.
+	MatchSequence = "sequence"
+	// MatchMessages requires the request messages to equal the recorded ones.
+	MatchMessages = "messages"
+	// MatchExact requires the whole request body to equal the recorded one,
+	// except for the model and streaming options.
+	MatchExact = "exact"
+)
+
+// Interaction is a request and its response as stored in a cassette, one JSON
+// object per line.
+type Interaction struct {
+	Request  json.RawMessage        `json:"request"`
+	Response *openai.ChatCompletion `json:"response,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// Recorder passes requests to a provider and appends every request and
+// response pair to a cassette file.
+type Recorder struct {
+	provider Provider
+	path     string
+	mu       sync.Mutex
+}
+
+// NewRecorder creates the cassette at path, replacing an existing one.
+func NewRecorder(provider Provider, path string) (*Recorder, error) {
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to create cassette: %w", err)
+	}
+
+	return &Recorder{provider: provider, path: path}, nil
+}
+
+func (r *Recorder) GenerateContent(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	resp, err := r.provider.GenerateContent(ctx, params)
+
+	return resp, r.record(params, resp, err)
+}
+
+func (r *Recorder) GenerateContentStream(ctx context.Context, params openai.ChatCompletionNewParams, onDelta func(delta string)) (*openai.ChatCompletion, error) {
+	resp, err := r.provider.GenerateContentStream(ctx, params, onDelta)
+
+	return resp, r.record(params, resp, err)
+}
+
+func (r *Recorder) ContextWindow() int {
+	return r.provider.ContextWindow()
+}
+
+// record appends the interaction and returns the error of the call, or the
+// error of writing the cassette.
+func (r *Recorder) record(params openai.ChatCompletionNewParams, resp *openai.ChatCompletion, callErr error) error {
+	request, err := json.Marshal(params)
+	if err != nil {
+		return errors.Join(callErr, fmt.Errorf("failed to marshal request: %w", err))
+	}
+
+	interaction := Interaction{Request: request, Response: resp}
+	if callErr != nil {
+		interaction.Response = nil
+		interaction.Error = callErr.Error()
+	}
+
+	line, err := json.Marshal(interaction)
+	if err != nil {
+		return errors.Join(callErr, fmt.Errorf("failed to marshal interaction: %w", err))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return errors.Join(callErr, fmt.Errorf("failed to open cassette: %w", err))
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return errors.Join(callErr, fmt.Errorf("failed to write cassette: %w", err))
+	}
+
+	return callErr
+}
+
+// Replayer serves the responses of a cassette instead of calling a model.
+// Every recorded interaction is used at most once.
+type Replayer struct {
+	path          string
+	match         string
+	contextWindow int
+	interactions  []Interaction
+	used          []bool
+	requests      int
+	mu            sync.Mutex
+}
+
+// NewReplayer loads the cassette at path. match is one of MatchSequence,
+// MatchMessages and MatchExact.
+func NewReplayer(path, match string, contextWindow int) (*Replayer, error) {
+	switch match {
+	case MatchSequence, MatchMessages, MatchExact:
+	default:
+		return nil, fmt.Errorf("unknown replay match rule: %s", match)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette: %w", err)
+	}
+	defer f.Close()
+
+	var interactions []Interaction
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var i Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &i); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette line %d: %w", line, err)
+		}
+
+		if i.Response == nil && i.Error == "" {
+			return nil, fmt.Errorf("invalid cassette line %d: neither response nor error recorded", line)
+		}
+
+		interactions = append(interactions, i)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	return &Replayer{
+		path:          path,
+		match:         match,
+		contextWindow: contextWindow,
+		interactions:  interactions,
+		used:          make([]bool, len(interactions)),
+	}, nil
+}
+
+func (r *Replayer) GenerateContent(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	request, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	key, err := matchKey(request, r.match)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests++
+
+	for i, interaction := range r.interactions {
+		if r.used[i] {
+			continue
+		}
+
+		recorded, err := matchKey(interaction.Request, r.match)
+		if err != nil {
+			return nil, err
+		}
+
+		if recorded != key {
+			continue
+		}
+
+		r.used[i] = true
+
+		if interaction.Error != "" {
+			return nil, errors.New(interaction.Error)
+		}
+
+		resp := *interaction.Response
+		return &resp, nil
+	}
+
+	return nil, fmt.Errorf("no recorded response in %s matches request %d (match rule %q)", r.path, r.requests, r.match)
+}
+
+// GenerateContentStream replays the response and passes its content to
+// onDelta as a single delta.
+func (r *Replayer) GenerateContentStream(ctx context.Context, params openai.ChatCompletionNewParams, onDelta func(delta string)) (*openai.ChatCompletion, error) {
+	resp, err := r.GenerateContent(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if onDelta != nil && len(resp.Choices) > 0 && resp.Choices[0].Message.Content != "" {
+		onDelta(resp.Choices[0].Message.Content)
+	}
+
+	return resp, nil
+}
+
+func (r *Replayer) ContextWindow() int {
+	return r.contextWindow
+}
+
+// matchKey reduces a request body to the parts compared by the match rule,
+// in a canonical form.
+func matchKey(request []byte, match string) (string, error) {
+	if match == MatchSequence {
+		return "", nil
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(request, &body); err != nil {
+		return "", fmt.Errorf("failed to parse request: %w", err)
+	}
+
+	var part any = body
+	if match == MatchMessages {
+		part = body["messages"]
+	} else {
+		delete(body, "model")
+		delete(body, "stream")
+		delete(body, "stream_options")
+	}
+
+	// Maps are marshaled with sorted keys, which makes the result canonical.
+	key, err := json.Marshal(part)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	return string(key), nil
+}
@@ -0,0 +1,151 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedProvider answers every request with the next scripted reply.
+type scriptedProvider struct {
+	replies []string
+	calls   int
+}
+
+func (p *scriptedProvider) GenerateContent(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	reply := p.replies[p.calls]
+	p.calls++
+
+	if reply == "" {
+		return nil, errors.New("503 Service Unavailable")
+	}
+
+	return &openai.ChatCompletion{
+		ID:      fmt.Sprintf("gen-%d", p.calls),
+		Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: "assistant", Content: reply}}},
+	}, nil
+}
+
+func (p *scriptedProvider) GenerateContentStream(ctx context.Context, params openai.ChatCompletionNewParams, onDelta func(delta string)) (*openai.ChatCompletion, error) {
+	return p.GenerateContent(ctx, params)
+}
+
+func (p *scriptedProvider) ContextWindow() int {
+	return 1000
+}
+
+func request(messages ...string) openai.ChatCompletionNewParams {
+	params := openai.ChatCompletionNewParams{MaxTokens: openai.Int(100)}
+	for _, m := range messages {
+		params.Messages = append(params.Messages, openai.UserMessage(m))
+	}
+
+	return params
+}
+
+func TestRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+
+	rec, err := NewRecorder(&scriptedProvider{replies: []string{"first", "", "second"}}, path)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	_, err = rec.GenerateContent(ctx, request("a"))
+	require.NoError(t, err)
+	_, err = rec.GenerateContent(ctx, request("b"))
+	require.Error(t, err)
+	_, err = rec.GenerateContentStream(ctx, request("a", "c"), nil)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		match    string
+		requests []openai.ChatCompletionNewParams
+		want     []string
+	}{
+		{
+			name:     "sequence ignores the request",
+			match:    MatchSequence,
+			requests: []openai.ChatCompletionNewParams{request("x"), request("y"), request("z")},
+			want:     []string{"first", "error: 503 Service Unavailable", "second"},
+		},
+		{
+			name:     "messages in any order",
+			match:    MatchMessages,
+			requests: []openai.ChatCompletionNewParams{request("a", "c"), request("a"), request("a")},
+			want:     []string{"second", "first", "error: no recorded response"},
+		},
+		{
+			name:  "exact compares the other parameters",
+			match: MatchExact,
+			requests: []openai.ChatCompletionNewParams{
+				{Messages: request("a").Messages, MaxTokens: openai.Int(5)},
+				request("a"),
+			},
+			want: []string{"error: no recorded response", "first"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReplayer(path, tc.match, 1000)
+			require.NoError(t, err)
+
+			for i, req := range tc.requests {
+				var got string
+
+				resp, err := r.GenerateContentStream(ctx, req, func(delta string) {})
+				if err != nil {
+					got = "error: " + err.Error()
+				} else {
+					got = resp.Choices[0].Message.Content
+				}
+
+				assert.Contains(t, got, tc.want[i])
+			}
+		})
+	}
+}
+
+func TestNewReplayer_UnknownMatch(t *testing.T) {
+	_, err := NewReplayer("missing.jsonl", "fuzzy", 0)
+	require.Error(t, err)
+}
+
+func TestNewReplayer_InvalidCassette(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "invalid json",
+			content: `{"request":{},"error":"503 Service Unavailable"}` + "\n\n" + `{"request":`,
+			wantErr: "failed to parse cassette line 3",
+		},
+		{
+			name:    "neither response nor error",
+			content: `{"request":{},"error":"503 Service Unavailable"}` + "\n\n" + `{"request":{}}`,
+			wantErr: "invalid cassette line 3: neither response nor error recorded",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "session.jsonl")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o644))
+
+			_, err := NewReplayer(path, MatchSequence, 0)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package llm
+
+import "strings"
+
+// defaultContextWindow is assumed for models missing from contextWindows.
+const defaultContextWindow = 32_768
+
+// contextWindows holds the context length in tokens of commonly used models.
+var contextWindows = map[string]int{
+	"openai/gpt-4o":                     128_000,
+	"openai/gpt-4o-mini":                128_000,
+	"openai/gpt-4.1":                    1_047_576,
+	"openai/gpt-4.1-mini":               1_047_576,
+	"openai/gpt-4-turbo":                128_000,
+	"openai/gpt-4":                      8_191,
+	"openai/o3-mini":                    200_000,
+	"anthropic/claude-3.5-sonnet":       200_000,
+	"anthropic/claude-3.7-sonnet":       200_000,
+	"anthropic/claude-sonnet-4":         200_000,
+	"anthropic/claude-opus-4":           200_000,
+	"google/gemini-2.0-flash-001":       1_048_576,
+	"google/gemini-2.5-flash":           1_048_576,
+	"google/gemini-2.5-pro":             1_048_576,
+	"deepseek/deepseek-chat":            163_840,
+	"meta-llama/llama-3.1-70b-instruct": 131_072,
+	"mistralai/mistral-large":           128_000,
+}
+
+// ContextWindow returns the context length of the model in tokens. Variants
+// such as "openai/gpt-4o:free" use the window of their base model.
+func ContextWindow(model string) int {
+	model, _, _ = strings.Cut(model, ":")
+
+	if n, ok := contextWindows[model]; ok {
+		return n
+	}
+
+	return defaultContextWindow
+}
+
+func (c *OpenRouter) ContextWindow() int {
+	return ContextWindow(c.cfg.Model)
+}
@@ -0,0 +1,25 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextWindow(t *testing.T) {
+	testCases := []struct {
+		model string
+		want  int
+	}{
+		{model: "openai/gpt-4o", want: 128_000},
+		{model: "openai/gpt-4o:free", want: 128_000},
+		{model: "anthropic/claude-sonnet-4", want: 200_000},
+		{model: "unknown/model", want: defaultContextWindow},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.model, func(t *testing.T) {
+			assert.Equal(t, tc.want, ContextWindow(tc.model))
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/openai/openai-go"
+)
+
+// Model is a provider serving a single named model.
+type Model struct {
+	Name     string
+	Provider Provider
+}
+
+// Fallback sends requests to the first of several models and moves on to the
+// next one when a model fails, e.g. because it is down or rejects the
+// response schema. A model that failed is not asked again in the session.
+type Fallback struct {
+	models     []Model
+	current    int
+	onFallback func(ctx context.Context, failed string, err error)
+}
+
+// NewFallback creates a provider trying models in order. onFallback, if not
+// nil, is called when a model fails and the next one is tried.
+func NewFallback(models []Model, onFallback func(ctx context.Context, failed string, err error)) *Fallback {
+	return &Fallback{models: models, onFallback: onFallback}
+}
+
+func (f *Fallback) GenerateContent(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	return f.do(ctx, func(p Provider) (*openai.ChatCompletion, error) {
+		return p.GenerateContent(ctx, params)
+	})
+}
+
+func (f *Fallback) GenerateContentStream(ctx context.Context, params openai.ChatCompletionNewParams, onDelta func(delta string)) (*openai.ChatCompletion, error) {
+	return f.do(ctx, func(p Provider) (*openai.ChatCompletion, error) {
+		return p.GenerateContentStream(ctx, params, onDelta)
+	})
+}
+
+// ContextWindow returns the smallest context window of the models, so the
+// history fits whichever model ends up answering.
+func (f *Fallback) ContextWindow() int {
+	window := 0
+	for _, m := range f.models {
+		if w := m.Provider.ContextWindow(); window == 0 || w < window {
+			window = w
+		}
+	}
+
+	return window
+}
+
+func (f *Fallback) do(ctx context.Context, call func(p Provider) (*openai.ChatCompletion, error)) (*openai.ChatCompletion, error) {
+	var errs []error
+
+	for ; f.current < len(f.models); f.current++ {
+		m := f.models[f.current]
+
+		resp, err := call(m.Provider)
+		if err == nil {
+			if resp.Model == "" {
+				resp.Model = m.Name
+			}
+
+			return resp, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", m.Name, err))
+
+		if !canFallBack(ctx, err) || f.current == len(f.models)-1 {
+			break
+		}
+
+		if f.onFallback != nil {
+			f.onFallback(ctx, m.Name, err)
+		}
+	}
+
+	if len(errs) == 1 {
+		return nil, errs[0]
+	}
+
+	return nil, fmt.Errorf("all models failed: %w", errors.Join(errs...))
+}
+
+// canFallBack reports whether another model may succeed where one failed.
+// Account errors and interrupted streams are the same for every model.
+func canFallBack(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, errStreamStarted) {
+		return false
+	}
+
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusUnauthorized, http.StatusPaymentRequired, http.StatusForbidden:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,91 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFallback(t *testing.T) {
+	tests := []struct {
+		name        string
+		primaryErrs []error
+		backupErrs  []error
+		wantModel   string
+		wantFailed  []string
+		wantErr     string
+	}{
+		{
+			name:      "primary answers",
+			wantModel: "primary",
+		},
+		{
+			name:        "primary down",
+			primaryErrs: []error{apiError(503, nil)},
+			wantModel:   "backup",
+			wantFailed:  []string{"primary"},
+		},
+		{
+			name:        "schema rejected",
+			primaryErrs: []error{apiError(400, nil)},
+			wantModel:   "backup",
+			wantFailed:  []string{"primary"},
+		},
+		{
+			name:        "unauthorized",
+			primaryErrs: []error{apiError(401, nil)},
+			wantErr:     "primary: POST",
+		},
+		{
+			name:        "all models fail",
+			primaryErrs: []error{apiError(404, nil)},
+			backupErrs:  []error{errors.New("connection reset")},
+			wantFailed:  []string{"primary"},
+			wantErr:     "all models failed: primary: POST \"/chat/completions\": 404 Not Found \nbackup: connection reset",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var failed []string
+			f := NewFallback([]Model{
+				{Name: "primary", Provider: &failingProvider{errs: tt.primaryErrs}},
+				{Name: "backup", Provider: &failingProvider{errs: tt.backupErrs}},
+			}, func(ctx context.Context, model string, err error) {
+				failed = append(failed, model)
+			})
+
+			resp, err := f.GenerateContent(context.Background(), openai.ChatCompletionNewParams{})
+			assert.Equal(t, tt.wantFailed, failed)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantModel, resp.Model)
+		})
+	}
+}
+
+func TestFallback_StaysOnBackup(t *testing.T) {
+	primary := &failingProvider{errs: []error{apiError(503, nil)}}
+	backup := &failingProvider{}
+
+	f := NewFallback([]Model{{Name: "primary", Provider: primary}, {Name: "backup", Provider: backup}}, nil)
+
+	for range 3 {
+		resp, err := f.GenerateContent(context.Background(), openai.ChatCompletionNewParams{})
+		require.NoError(t, err)
+		assert.Equal(t, "backup", resp.Model)
+	}
+
+	assert.Equal(t, 1, primary.calls)
+	assert.Equal(t, 3, backup.calls)
+}
@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// CachedModels returns the IDs of the models offered by OpenRouter. The list is
+// read from the cache file at path while it is younger than ttl, and fetched
+// and cached again otherwise. A stale cache is used when fetching fails.
+func CachedModels(ctx context.Context, path string, ttl time.Duration) ([]string, error) {
+	cached, modTime, cacheErr := readModels(path)
+	if cacheErr == nil && time.Since(modTime) < ttl {
+		return cached, nil
+	}
+
+	models, err := fetchModels(ctx, BaseURL+"/models")
+	if err != nil {
+		if cacheErr == nil {
+			return cached, nil
+		}
+
+		return nil, err
+	}
+
+	if err := writeModels(path, models); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+func fetchModels(ctx context.Context, url string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch models: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch models: %s", resp.Status)
+	}
+
+	var body struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode models: %w", err)
+	}
+
+	models := make([]string, 0, len(body.Data))
+	for _, m := range body.Data {
+		models = append(models, m.ID)
+	}
+	sort.Strings(models)
+
+	return models, nil
+}
+
+func readModels(path string) ([]string, time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var models []string
+	if err := json.Unmarshal(data, &models); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return models, info.ModTime(), nil
+}
+
+func writeModels(path string, models []string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	data, err := json.Marshal(models)
+	if err != nil {
+		return fmt.Errorf("failed to marshal models: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write models cache: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchModels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":[{"id":"openai/gpt-4o"},{"id":"anthropic/claude-sonnet-4"}]}`))
+	}))
+	defer srv.Close()
+
+	models, err := fetchModels(context.Background(), srv.URL)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"anthropic/claude-sonnet-4", "openai/gpt-4o"}, models)
+}
+
+func TestCachedModels_FreshCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ga", "models.json")
+	require.NoError(t, writeModels(path, []string{"openai/gpt-4o"}))
+
+	models, err := CachedModels(context.Background(), path, time.Hour)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"openai/gpt-4o"}, models)
+}
+
+func TestCachedModels_StaleCacheOnFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "models.json")
+	require.NoError(t, writeModels(path, []string{"openai/gpt-4o"}))
+
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(path, old, old))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	models, err := CachedModels(ctx, path, time.Hour)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"openai/gpt-4o"}, models)
+}
@@ -16,10 +16,9 @@ type OpenRouter struct {
 }
 
 type OpenRouterConfig struct {
-	APIKey    string
-	Model     string
-	MaxTokens int64
-	Timeout   time.Duration
+	APIKey  string
+	Model   string
+	Timeout time.Duration
 }
 
 func NewOpenRouter(cfg *OpenRouterConfig) *OpenRouter {
@@ -27,6 +26,8 @@ func NewOpenRouter(cfg *OpenRouterConfig) *OpenRouter {
 		option.WithAPIKey(cfg.APIKey),
 		option.WithBaseURL(BaseURL),
 		option.WithRequestTimeout(cfg.Timeout),
+		// Retries are done by the Retrier, see NewRetrier.
+		option.WithMaxRetries(0),
 	)
 
 	return &OpenRouter{
@@ -37,7 +38,6 @@ func NewOpenRouter(cfg *OpenRouterConfig) *OpenRouter {
 
 func (c *OpenRouter) GenerateContent(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
 	params.Model = c.cfg.Model
-	params.MaxTokens.Value = c.cfg.MaxTokens
 
 	return c.client.Chat.Completions.New(ctx, params)
 }
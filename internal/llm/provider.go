@@ -0,0 +1,16 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/openai/openai-go"
+)
+
+// Provider generates chat completions. It is implemented by OpenRouter and by
+// the Recorder and Replayer used to capture and reproduce sessions.
+type Provider interface {
+	GenerateContent(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error)
+	GenerateContentStream(ctx context.Context, params openai.ChatCompletionNewParams, onDelta func(delta string)) (*openai.ChatCompletion, error)
+	// ContextWindow returns the context length of the model in tokens.
+	ContextWindow() int
+}
@@ -0,0 +1,214 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/openai/openai-go"
+)
+
+// RetryConfig controls how failed model calls are retried.
+type RetryConfig struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+	// BaseDelay is the delay before the first retry, doubled for every
+	// further one.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff. A Retry-After longer than MaxDelay is not
+	// waited for.
+	MaxDelay time.Duration
+	// OnRetry is called before waiting for the next attempt.
+	OnRetry func(ctx context.Context, r *Retry)
+}
+
+// Retry describes a failed attempt that is about to be retried.
+type Retry struct {
+	// Attempt is the number of the upcoming retry, starting at 1.
+	Attempt int
+	Delay   time.Duration
+	Err     error
+}
+
+// Retrier retries the calls of a provider that fail with a rate limit, a
+// server or a network error, waiting with exponential backoff and jitter.
+type Retrier struct {
+	provider Provider
+	cfg      *RetryConfig
+}
+
+func NewRetrier(provider Provider, cfg *RetryConfig) *Retrier {
+	return &Retrier{provider: provider, cfg: cfg}
+}
+
+func (r *Retrier) GenerateContent(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	return r.do(ctx, func() (*openai.ChatCompletion, error) {
+		return r.provider.GenerateContent(ctx, params)
+	})
+}
+
+// GenerateContentStream retries a stream only as long as none of it was
+// passed to onDelta, as the text can't be taken back.
+func (r *Retrier) GenerateContentStream(ctx context.Context, params openai.ChatCompletionNewParams, onDelta func(delta string)) (*openai.ChatCompletion, error) {
+	streamed := false
+
+	return r.do(ctx, func() (*openai.ChatCompletion, error) {
+		resp, err := r.provider.GenerateContentStream(ctx, params, func(delta string) {
+			streamed = true
+			if onDelta != nil {
+				onDelta(delta)
+			}
+		})
+		if err != nil && streamed {
+			return nil, fmt.Errorf("%w: %w", errStreamStarted, err)
+		}
+
+		return resp, err
+	})
+}
+
+func (r *Retrier) ContextWindow() int {
+	return r.provider.ContextWindow()
+}
+
+// errStreamStarted stops retrying a stream that already delivered text.
+var errStreamStarted = errors.New("stream interrupted after the response started")
+
+func (r *Retrier) do(ctx context.Context, call func() (*openai.ChatCompletion, error)) (*openai.ChatCompletion, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := call()
+		if err == nil {
+			return resp, nil
+		}
+
+		if ctx.Err() != nil || errors.Is(err, errStreamStarted) {
+			return nil, err
+		}
+
+		retryable, retryAfter := classify(err)
+		if !retryable {
+			return nil, describe(err)
+		}
+
+		if attempt >= r.cfg.MaxRetries {
+			if attempt == 0 {
+				return nil, err
+			}
+
+			return nil, fmt.Errorf("giving up after %d retries: %w", attempt, err)
+		}
+
+		if retryAfter > r.cfg.MaxDelay {
+			return nil, fmt.Errorf("rate limited, the provider asks to retry after %s: %w", retryAfter, err)
+		}
+
+		delay := max(retryAfter, backoff(attempt, r.cfg.BaseDelay, r.cfg.MaxDelay))
+
+		if r.cfg.OnRetry != nil {
+			r.cfg.OnRetry(ctx, &Retry{Attempt: attempt + 1, Delay: delay, Err: err})
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before the given retry: base doubled per attempt,
+// capped at limit, with a random jitter of up to half the delay.
+func backoff(attempt int, base, limit time.Duration) time.Duration {
+	delay := limit
+	if attempt < 32 {
+		delay = min(base<<attempt, limit)
+	}
+
+	if half := int64(delay / 2); half > 0 {
+		delay = time.Duration(half + rand.Int64N(half+1))
+	}
+
+	return delay
+}
+
+// classify reports whether err is worth retrying and how long the provider
+// asked to wait, if it did.
+func classify(err error) (bool, time.Duration) {
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusRequestTimeout,
+			apiErr.StatusCode == http.StatusConflict,
+			apiErr.StatusCode == http.StatusTooManyRequests,
+			apiErr.StatusCode >= http.StatusInternalServerError:
+			return true, retryAfter(apiErr.Response)
+		default:
+			return false, 0
+		}
+	}
+
+	// A timeout of a single request, the session context is checked before.
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true, 0
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true, 0
+	}
+
+	return false, 0
+}
+
+// retryAfter parses the Retry-After header given in seconds or as a date.
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+
+	return 0
+}
+
+// describe explains errors that retrying can't fix.
+func describe(err error) error {
+	var apiErr *openai.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized:
+		return fmt.Errorf("authentication failed, check the API key: %w", err)
+	case http.StatusPaymentRequired:
+		return fmt.Errorf("insufficient credits: %w", err)
+	case http.StatusForbidden:
+		return fmt.Errorf("access denied: %w", err)
+	case http.StatusNotFound:
+		return fmt.Errorf("model not found, check the model name: %w", err)
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return fmt.Errorf("request rejected, the model may not support tools or the response schema: %w", err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,207 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingProvider fails with the given errors before answering, optionally
+// streaming some text before each failure.
+type failingProvider struct {
+	errs        []error
+	streamFirst bool
+	calls       int
+}
+
+func (p *failingProvider) GenerateContent(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	p.calls++
+	if p.calls <= len(p.errs) {
+		return nil, p.errs[p.calls-1]
+	}
+
+	return &openai.ChatCompletion{Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "ok"}}}}, nil
+}
+
+func (p *failingProvider) GenerateContentStream(ctx context.Context, params openai.ChatCompletionNewParams, onDelta func(delta string)) (*openai.ChatCompletion, error) {
+	if p.streamFirst {
+		onDelta("partial")
+	}
+
+	return p.GenerateContent(ctx, params)
+}
+
+func (p *failingProvider) ContextWindow() int {
+	return 1000
+}
+
+func apiError(status int, header http.Header) *openai.Error {
+	return &openai.Error{
+		StatusCode: status,
+		Request:    &http.Request{Method: http.MethodPost, URL: &url.URL{Path: "/chat/completions"}},
+		Response:   &http.Response{StatusCode: status, Header: header},
+	}
+}
+
+func TestRetrier(t *testing.T) {
+	tests := []struct {
+		name        string
+		errs        []error
+		streamFirst bool
+		wantCalls   int
+		wantRetries []time.Duration
+		wantErr     string
+	}{
+		{
+			name:      "success",
+			wantCalls: 1,
+		},
+		{
+			name:        "rate limit and server error",
+			errs:        []error{apiError(429, nil), apiError(502, nil)},
+			wantCalls:   3,
+			wantRetries: []time.Duration{0, 0},
+		},
+		{
+			name:        "retry after",
+			errs:        []error{apiError(429, http.Header{"Retry-After": []string{"1"}})},
+			wantCalls:   2,
+			wantRetries: []time.Duration{time.Second},
+		},
+		{
+			name:        "request timeout",
+			errs:        []error{context.DeadlineExceeded},
+			wantCalls:   2,
+			wantRetries: []time.Duration{0},
+		},
+		{
+			name:        "retries exhausted",
+			errs:        []error{apiError(500, nil), apiError(500, nil), apiError(500, nil), apiError(500, nil)},
+			wantCalls:   3,
+			wantRetries: []time.Duration{0, 0},
+			wantErr:     "giving up after 2 retries",
+		},
+		{
+			name:      "retry after too long",
+			errs:      []error{apiError(429, http.Header{"Retry-After": []string{"3600"}})},
+			wantCalls: 1,
+			wantErr:   "rate limited, the provider asks to retry after 1h0m0s",
+		},
+		{
+			name:      "unauthorized",
+			errs:      []error{apiError(401, nil)},
+			wantCalls: 1,
+			wantErr:   "authentication failed, check the API key",
+		},
+		{
+			name:      "unknown model",
+			errs:      []error{apiError(404, nil)},
+			wantCalls: 1,
+			wantErr:   "model not found",
+		},
+		{
+			name:      "bad request",
+			errs:      []error{apiError(400, nil)},
+			wantCalls: 1,
+			wantErr:   "request rejected",
+		},
+		{
+			name:      "other error",
+			errs:      []error{errors.New("no matching interaction")},
+			wantCalls: 1,
+			wantErr:   "no matching interaction",
+		},
+		{
+			name:        "stream already started",
+			errs:        []error{apiError(502, nil)},
+			streamFirst: true,
+			wantCalls:   1,
+			wantErr:     "stream interrupted after the response started",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &failingProvider{errs: tt.errs, streamFirst: tt.streamFirst}
+
+			var retries []time.Duration
+			r := NewRetrier(provider, &RetryConfig{
+				MaxRetries: 2,
+				MaxDelay:   time.Minute,
+				OnRetry: func(ctx context.Context, r *Retry) {
+					retries = append(retries, r.Delay.Truncate(time.Second))
+				},
+			})
+
+			resp, err := r.GenerateContentStream(context.Background(), openai.ChatCompletionNewParams{}, func(string) {})
+
+			assert.Equal(t, tt.wantCalls, provider.calls)
+			assert.Equal(t, tt.wantRetries, retries)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "ok", resp.Choices[0].Message.Content)
+		})
+	}
+}
+
+func TestRetrier_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r := NewRetrier(&failingProvider{errs: []error{apiError(503, nil)}}, &RetryConfig{
+		MaxRetries: 2,
+		BaseDelay:  time.Hour,
+		MaxDelay:   time.Hour,
+		OnRetry: func(ctx context.Context, r *Retry) {
+			cancel()
+		},
+	})
+
+	_, err := r.GenerateContent(ctx, openai.ChatCompletionNewParams{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "503")
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second} {
+		delay := backoff(attempt, time.Second, 8*time.Second)
+		assert.GreaterOrEqual(t, delay, want/2)
+		assert.LessOrEqual(t, delay, want)
+	}
+
+	assert.LessOrEqual(t, backoff(100, time.Second, 10*time.Second), 10*time.Second)
+}
+
+func TestRetryAfter(t *testing.T) {
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: 0},
+		{value: "7", want: 7 * time.Second},
+		{value: "-3", want: 0},
+		{value: "soon", want: 0},
+		{value: date, want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got := retryAfter(&http.Response{Header: http.Header{"Retry-After": []string{tt.value}}})
+			assert.InDelta(t, tt.want, got, float64(2*time.Second))
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openai/openai-go"
+)
+
+// GenerateContentStream works like GenerateContent but receives the response
+// as a stream of server-sent events. onDelta is called with every piece of
+// assistant text as soon as it arrives, tool call deltas are accumulated into
+// the returned completion.
+func (c *OpenRouter) GenerateContentStream(ctx context.Context, params openai.ChatCompletionNewParams, onDelta func(delta string)) (*openai.ChatCompletion, error) {
+	params.Model = c.cfg.Model
+	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{
+		IncludeUsage: openai.Bool(true),
+	}
+
+	stream := c.client.Chat.Completions.NewStreaming(ctx, params)
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	acc := newStreamAccumulator()
+	for stream.Next() {
+		chunk := stream.Current()
+		acc.add(chunk)
+
+		if onDelta == nil {
+			continue
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.Index == 0 && choice.Delta.Content != "" {
+				onDelta(choice.Delta.Content)
+			}
+		}
+	}
+
+	if err := stream.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(acc.completion.Choices) == 0 {
+		return nil, fmt.Errorf("stream ended without a response")
+	}
+
+	return &acc.completion, nil
+}
+
+// streamAccumulator merges chunks into a single completion. Some providers
+// stream every tool call with index 0 and only tell them apart by ID, so a
+// new ID on an index that already holds a call starts a new call.
+type streamAccumulator struct {
+	completion openai.ChatCompletion
+	// slots maps the tool call index used by the stream to the position of
+	// the call in the accumulated message.
+	slots map[int64]int
+}
+
+func newStreamAccumulator() *streamAccumulator {
+	return &streamAccumulator{slots: make(map[int64]int)}
+}
+
+func (a *streamAccumulator) add(chunk openai.ChatCompletionChunk) {
+	cc := &a.completion
+
+	if cc.ID == "" {
+		cc.ID = chunk.ID
+	}
+	if chunk.Model != "" {
+		cc.Model = chunk.Model
+	}
+	if chunk.Created != 0 && cc.Created == 0 {
+		cc.Created = chunk.Created
+	}
+	if chunk.Usage.TotalTokens > 0 {
+		cc.Usage.PromptTokens = chunk.Usage.PromptTokens
+		cc.Usage.CompletionTokens = chunk.Usage.CompletionTokens
+		cc.Usage.TotalTokens = chunk.Usage.TotalTokens
+	}
+
+	for _, choice := range chunk.Choices {
+		// Only the first choice is used by the agent.
+		if choice.Index != 0 {
+			continue
+		}
+
+		if len(cc.Choices) == 0 {
+			cc.Choices = make([]openai.ChatCompletionChoice, 1)
+		}
+		target := &cc.Choices[0]
+
+		if choice.FinishReason != "" {
+			target.FinishReason = choice.FinishReason
+		}
+
+		target.Message.Content += choice.Delta.Content
+		target.Message.Refusal += choice.Delta.Refusal
+
+		for _, delta := range choice.Delta.ToolCalls {
+			a.addToolCall(&target.Message, delta)
+		}
+	}
+}
+
+func (a *streamAccumulator) addToolCall(message *openai.ChatCompletionMessage, delta openai.ChatCompletionChunkChoiceDeltaToolCall) {
+	slot, ok := a.slots[delta.Index]
+
+	startsNew := !ok || (delta.ID != "" && message.ToolCalls[slot].ID != "" && message.ToolCalls[slot].ID != delta.ID)
+	if startsNew {
+		message.ToolCalls = append(message.ToolCalls, openai.ChatCompletionMessageToolCall{})
+		slot = len(message.ToolCalls) - 1
+		a.slots[delta.Index] = slot
+	}
+
+	call := &message.ToolCalls[slot]
+	if delta.ID != "" {
+		call.ID = delta.ID
+	}
+	call.Function.Name += delta.Function.Name
+	call.Function.Arguments += delta.Function.Arguments
+}
@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamAccumulator(t *testing.T) {
+	testCases := []struct {
+		name        string
+		chunks      []string
+		wantContent string
+		wantCalls   [][3]string
+		wantTokens  int64
+	}{
+		{
+			name: "content only",
+			chunks: []string{
+				`{"id":"1","choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"}}]}`,
+				`{"id":"1","choices":[{"index":0,"delta":{"content":"lo"},"finish_reason":"stop"}]}`,
+				`{"id":"1","choices":[],"usage":{"prompt_tokens":10,"completion_tokens":2,"total_tokens":12}}`,
+			},
+			wantContent: "Hello",
+			wantTokens:  12,
+		},
+		{
+			name: "indexed tool calls",
+			chunks: []string{
+				`{"id":"1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"a","type":"function","function":{"name":"git_command","arguments":""}}]}}]}`,
+				`{"id":"1","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"b","type":"function","function":{"name":"read_file","arguments":"{\"pa"}}]}}]}`,
+				`{"id":"1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"args\":[\"status\"]}"}}]}}]}`,
+				`{"id":"1","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"function":{"arguments":"th\":\"a\"}"}}]},"finish_reason":"tool_calls"}]}`,
+			},
+			wantCalls: [][3]string{
+				{"a", "git_command", `{"args":["status"]}`},
+				{"b", "read_file", `{"path":"a"}`},
+			},
+		},
+		{
+			name: "tool calls reusing index",
+			chunks: []string{
+				`{"id":"1","choices":[{"index":0,"delta":{"content":"Checking"}}]}`,
+				`{"id":"1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"a","function":{"name":"git_command","arguments":"{\"args\":"}}]}}]}`,
+				`{"id":"1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"[\"diff\"]}"}}]}}]}`,
+				`{"id":"1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"b","function":{"name":"list_files","arguments":"{}"}}]}}]}`,
+			},
+			wantContent: "Checking",
+			wantCalls: [][3]string{
+				{"a", "git_command", `{"args":["diff"]}`},
+				{"b", "list_files", `{}`},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			acc := newStreamAccumulator()
+
+			for _, raw := range tc.chunks {
+				var chunk openai.ChatCompletionChunk
+				require.NoError(t, json.Unmarshal([]byte(raw), &chunk))
+				acc.add(chunk)
+			}
+
+			require.Len(t, acc.completion.Choices, 1)
+			message := acc.completion.Choices[0].Message
+
+			assert.Equal(t, tc.wantContent, message.Content)
+			assert.Equal(t, tc.wantTokens, acc.completion.Usage.TotalTokens)
+
+			require.Len(t, message.ToolCalls, len(tc.wantCalls))
+			for i, want := range tc.wantCalls {
+				call := message.ToolCalls[i]
+				assert.Equal(t, want, [3]string{call.ID, call.Function.Name, call.Function.Arguments})
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package style
+
+import "slices"
+
+// minDetectShare is the share of commit subjects that must follow a preset
+// for Detect to pick it.
+const minDetectShare = 0.5
+
+// Detect picks the preset that best matches the given commit subjects,
+// most recent first. Conventional is returned when there is no history to
+// learn from and Plain when no preset is followed consistently.
+func Detect(subjects []string) string {
+	if len(subjects) == 0 {
+		return Conventional
+	}
+
+	counts := make(map[string]int)
+	for _, subject := range subjects {
+		counts[classify(subject)]++
+	}
+
+	best, bestCount := Plain, 0
+	for _, name := range []string{Gitmoji, Conventional, Kernel} {
+		if counts[name] > bestCount {
+			best, bestCount = name, counts[name]
+		}
+	}
+
+	if float64(bestCount)/float64(len(subjects)) < minDetectShare {
+		return Plain
+	}
+
+	return best
+}
+
+func classify(subject string) string {
+	if gitmojiHeaderRgx.MatchString(subject) {
+		return Gitmoji
+	}
+
+	if match := typedHeaderRgx.FindStringSubmatch(subject); match != nil && slices.Contains(conventionalTypes, match[1]) {
+		return Conventional
+	}
+
+	if kernelHeaderRgx.MatchString(subject) {
+		return Kernel
+	}
+
+	return Plain
+}
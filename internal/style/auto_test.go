@@ -0,0 +1,46 @@
+package style
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name     string
+		subjects []string
+		want     string
+	}{
+		{
+			name: "no history",
+			want: Conventional,
+		},
+		{
+			name:     "conventional history",
+			subjects: []string{"feat(api): add refunds", "fix: handle nil", "Merge stuff", "docs: update readme"},
+			want:     Conventional,
+		},
+		{
+			name:     "gitmoji history",
+			subjects: []string{":sparkles: add refunds", "🐛 handle nil", "fix: typo"},
+			want:     Gitmoji,
+		},
+		{
+			name:     "kernel history",
+			subjects: []string{"net/ipv4: fix checksum", "docs: update howto", "mm: reduce lock contention"},
+			want:     Kernel,
+		},
+		{
+			name:     "mixed history",
+			subjects: []string{"Add refunds", "fix: handle nil", ":bug: typo", "Update readme"},
+			want:     Plain,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Detect(tc.subjects))
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package style
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	conventionalTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+	angularTypes      = []string{"build", "ci", "docs", "feat", "fix", "perf", "refactor", "test"}
+	gitmojis          = []string{
+		":sparkles:", ":bug:", ":ambulance:", ":memo:", ":art:", ":zap:", ":fire:", ":white_check_mark:",
+		":recycle:", ":wrench:", ":arrow_up:", ":arrow_down:", ":heavy_plus_sign:", ":heavy_minus_sign:",
+		":construction_worker:", ":green_heart:", ":lipstick:", ":lock:", ":rocket:", ":tada:",
+		":rotating_light:", ":truck:", ":pencil2:", ":bookmark:", ":boom:", ":rewind:", ":package:",
+	}
+)
+
+var (
+	typedHeaderRgx   = regexp.MustCompile(`^(\w+)(?:\([^)]+\))?!?: \S`)
+	gitmojiHeaderRgx = regexp.MustCompile(`^(:[a-z0-9_+-]+:|\p{So}\x{FE0F}?) \S`)
+	kernelHeaderRgx  = regexp.MustCompile(`^[\w./-]+(?:, [\w./-]+)*: \S`)
+)
+
+var subjectProperty = map[string]any{
+	"type":        "string",
+	"description": "Short summary of the change in imperative mood, without trailing period.",
+}
+
+var bodyProperty = map[string]any{
+	"type":        "array",
+	"items":       map[string]any{"type": "string"},
+	"description": "Body paragraphs explaining what and why, each wrapped at 72 characters. Empty array if not needed.",
+}
+
+var breakingProperty = map[string]any{
+	"type":        "string",
+	"description": "Description of the breaking change for users of the code. Empty string if the change is compatible.",
+}
+
+var footersProperty = map[string]any{
+	"type":        "array",
+	"description": "Footers such as 'Refs' or 'Reviewed-by', without the breaking change. Empty array if none.",
+	"items": map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"token": map[string]any{
+				"type":        "string",
+				"description": "Footer name, words joined by '-', e.g. 'Refs'.",
+			},
+			"value": map[string]any{
+				"type":        "string",
+				"description": "Footer value, e.g. '#123'.",
+			},
+		},
+		"required":             []string{"token", "value"},
+		"additionalProperties": false,
+	},
+}
+
+var presets = map[string]*Preset{
+	Conventional: {
+		Name: Conventional,
+		Guidance: "Use the Conventional Commits format: 'type(scope): subject'. The scope is optional. " +
+			"The subject is in imperative mood, lower case, without trailing period.",
+		Properties: map[string]any{
+			"type": map[string]any{
+				"type":        "string",
+				"enum":        conventionalTypes,
+				"description": "Kind of change.",
+			},
+			"scope": map[string]any{
+				"type":        "string",
+				"description": "Optional area of the codebase affected, e.g. 'api'. Empty string if none.",
+			},
+			"subject":  subjectProperty,
+			"body":     bodyProperty,
+			"breaking": breakingProperty,
+			"footers":  footersProperty,
+		},
+		format: func(m *Message) string {
+			return withScope(m.Type, m.Scope) + breakingMark(m) + ": " + m.Subject
+		},
+		validate: func(header string) []string {
+			return append(checkTypedHeader(header, typedHeaderRgx, conventionalTypes), checkHeaderLength(header, 100)...)
+		},
+	},
+
+	Angular: {
+		Name: Angular,
+		Guidance: "Use the Angular commit format: 'type(scope): subject'. The scope is optional. " +
+			"The subject is in imperative, present tense, starts with a lower case letter and has no trailing period.",
+		Properties: map[string]any{
+			"type": map[string]any{
+				"type":        "string",
+				"enum":        angularTypes,
+				"description": "Kind of change.",
+			},
+			"scope": map[string]any{
+				"type":        "string",
+				"description": "Optional name of the affected package or module. Empty string if none.",
+			},
+			"subject":  subjectProperty,
+			"body":     bodyProperty,
+			"breaking": breakingProperty,
+			"footers":  footersProperty,
+		},
+		format: func(m *Message) string {
+			return withScope(m.Type, m.Scope) + ": " + m.Subject
+		},
+		validate: func(header string) []string {
+			violations := checkTypedHeader(header, typedHeaderRgx, angularTypes)
+
+			if _, subject, ok := strings.Cut(header, ": "); ok {
+				if r, _ := utf8.DecodeRuneInString(subject); unicode.IsUpper(r) {
+					violations = append(violations, "subject must start with a lower case letter")
+				}
+
+				if strings.HasSuffix(subject, ".") {
+					violations = append(violations, "subject must not end with a period")
+				}
+			}
+
+			return append(violations, checkHeaderLength(header, 100)...)
+		},
+	},
+
+	Gitmoji: {
+		Name: Gitmoji,
+		Guidance: "Use the gitmoji format: ':emoji: subject', where the emoji shortcode describes the intention " +
+			"of the change (e.g. :sparkles: new feature, :bug: bug fix, :memo: documentation).",
+		Properties: map[string]any{
+			"type": map[string]any{
+				"type":        "string",
+				"enum":        gitmojis,
+				"description": "Gitmoji shortcode describing the intention of the change.",
+			},
+			"subject":  subjectProperty,
+			"body":     bodyProperty,
+			"breaking": breakingProperty,
+			"footers":  footersProperty,
+		},
+		format: func(m *Message) string {
+			return m.Type + " " + m.Subject
+		},
+		validate: func(header string) []string {
+			var violations []string
+			if !gitmojiHeaderRgx.MatchString(header) {
+				violations = append(violations, "subject line must start with a gitmoji followed by a space, e.g. ':sparkles: add refund flow'")
+			}
+
+			return append(violations, checkHeaderLength(header, 100)...)
+		},
+	},
+
+	Kernel: {
+		Name: Kernel,
+		Guidance: "Use the Linux kernel format: 'subsystem: summary', where subsystem is the affected area " +
+			"(e.g. 'net/ipv4' or 'docs'). The summary is in imperative mood without trailing period and the " +
+			"body explains the motivation for the change.",
+		Properties: map[string]any{
+			"scope": map[string]any{
+				"type":        "string",
+				"description": "Subsystem prefix, e.g. 'net/ipv4' or 'docs'.",
+			},
+			"subject": subjectProperty,
+			"body":    bodyProperty,
+			"footers": footersProperty,
+		},
+		format: func(m *Message) string {
+			return m.Scope + ": " + m.Subject
+		},
+		validate: func(header string) []string {
+			var violations []string
+			if !kernelHeaderRgx.MatchString(header) {
+				violations = append(violations, "subject line must have the form 'subsystem: summary'")
+			}
+
+			return append(violations, checkHeaderLength(header, 75)...)
+		},
+	},
+
+	Plain: {
+		Name: Plain,
+		Guidance: "Use a plain commit message: a capitalized subject line of at most 50 characters in imperative " +
+			"mood without trailing period, optionally followed by a blank line and a body.",
+		Properties: map[string]any{
+			"subject": subjectProperty,
+			"body":    bodyProperty,
+			"footers": footersProperty,
+		},
+		format: func(m *Message) string {
+			return m.Subject
+		},
+		validate: func(header string) []string {
+			var violations []string
+			if strings.HasSuffix(header, ".") {
+				violations = append(violations, "subject must not end with a period")
+			}
+
+			return append(violations, checkHeaderLength(header, 72)...)
+		},
+	},
+}
+
+var inferred = &Preset{
+	Name: "inferred",
+	Properties: map[string]any{
+		"header": map[string]any{
+			"type":        "string",
+			"description": "Complete subject line following the conventions of the commit history, e.g. 'feat(api): add refund endpoint'.",
+		},
+		"body":     bodyProperty,
+		"breaking": breakingProperty,
+		"footers":  footersProperty,
+	},
+	format: func(m *Message) string {
+		return m.Header
+	},
+	validate: func(header string) []string {
+		return nil
+	},
+}
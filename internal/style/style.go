@@ -0,0 +1,201 @@
+package style
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	Conventional = "conventional"
+	Gitmoji      = "gitmoji"
+	Angular      = "angular"
+	Kernel       = "kernel"
+	Plain        = "plain"
+	Auto         = "auto"
+)
+
+// breakingToken is the footer token of a breaking change description.
+const breakingToken = "BREAKING CHANGE"
+
+// Message holds the fields of a commit message as produced by the model.
+// Presets only use the fields declared in their schema.
+type Message struct {
+	// Header is the complete subject line, only used by Inferred.
+	Header  string   `json:"header"`
+	Type    string   `json:"type"`
+	Scope   string   `json:"scope"`
+	Subject string   `json:"subject"`
+	Body    []string `json:"body"`
+	// Breaking describes an incompatible change, empty if there is none.
+	Breaking string   `json:"breaking"`
+	Footers  []Footer `json:"footers"`
+}
+
+// Footer is a trailer line at the end of the message, e.g. "Refs: #123".
+type Footer struct {
+	Token string `json:"token"`
+	Value string `json:"value"`
+}
+
+type Preset struct {
+	Name       string
+	Guidance   string
+	Properties map[string]any
+	format     func(m *Message) string
+	validate   func(header string) []string
+}
+
+// Get returns the preset with the given name.
+func Get(name string) (*Preset, error) {
+	p, ok := presets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown commit style: %s", name)
+	}
+
+	return p, nil
+}
+
+// Inferred returns the preset used when no style is configured: the model
+// writes the subject line itself, following the commit history, and the rest
+// of the message is rendered from the fields like for the other presets.
+func Inferred() *Preset {
+	return inferred
+}
+
+// Names returns the names of all built-in presets.
+func Names() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Schema returns the JSON schema of the message fields used by the preset.
+func (p *Preset) Schema() map[string]any {
+	required := make([]string, 0, len(p.Properties))
+	for name := range p.Properties {
+		required = append(required, name)
+	}
+	sort.Strings(required)
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           p.Properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// Format renders the message fields into the final commit message: the
+// header, the body paragraphs and a block of footers, separated by blank
+// lines.
+func (p *Preset) Format(m *Message) string {
+	parts := []string{p.format(m)}
+
+	for _, paragraph := range m.Body {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			parts = append(parts, paragraph)
+		}
+	}
+
+	var footers []string
+	if breaking := strings.TrimSpace(m.Breaking); breaking != "" {
+		footers = append(footers, breakingToken+": "+breaking)
+	}
+
+	for _, f := range m.Footers {
+		token, value := strings.TrimSpace(f.Token), strings.TrimSpace(f.Value)
+		if token != "" && value != "" {
+			footers = append(footers, token+": "+value)
+		}
+	}
+
+	if len(footers) > 0 {
+		parts = append(parts, strings.Join(footers, "\n"))
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+// WithPrefix returns a copy of the preset that starts the subject line with
+// prefix, e.g. a ticket ID. The rest of the header follows the preset.
+func (p *Preset) WithPrefix(prefix string) *Preset {
+	c := *p
+
+	c.format = func(m *Message) string {
+		return prefix + " " + p.format(m)
+	}
+
+	c.validate = func(header string) []string {
+		rest, ok := strings.CutPrefix(header, prefix+" ")
+		if !ok {
+			return []string{fmt.Sprintf("subject line must start with %q", prefix+" ")}
+		}
+
+		return p.validate(rest)
+	}
+
+	return &c
+}
+
+// Validate checks that message follows the preset.
+func (p *Preset) Validate(message string) []string {
+	header, rest, _ := strings.Cut(strings.TrimSpace(message), "\n")
+
+	var violations []string
+
+	if strings.TrimSpace(header) == "" {
+		violations = append(violations, "subject line must not be empty")
+	}
+
+	if rest != "" && !strings.HasPrefix(rest, "\n") {
+		violations = append(violations, "subject line must be separated from the body by a blank line")
+	}
+
+	return append(violations, p.validate(header)...)
+}
+
+func checkHeaderLength(header string, limit int) []string {
+	if n := utf8.RuneCountInString(header); n > limit {
+		return []string{fmt.Sprintf("subject line must not be longer than %d characters, current length is %d", limit, n)}
+	}
+
+	return nil
+}
+
+func checkTypedHeader(header string, rgx *regexp.Regexp, types []string) []string {
+	match := rgx.FindStringSubmatch(header)
+	if match == nil {
+		return []string{"subject line must have the form 'type(scope): subject'"}
+	}
+
+	if !slices.Contains(types, match[1]) {
+		return []string{fmt.Sprintf("type %q is not allowed, must be one of: %s", match[1], strings.Join(types, ", "))}
+	}
+
+	return nil
+}
+
+// breakingMark returns the '!' marking a breaking change in the header.
+func breakingMark(m *Message) string {
+	if strings.TrimSpace(m.Breaking) != "" {
+		return "!"
+	}
+
+	return ""
+}
+
+func withScope(prefix, scope string) string {
+	if scope = strings.TrimSpace(scope); scope != "" {
+		return prefix + "(" + scope + ")"
+	}
+
+	return prefix
+}
@@ -0,0 +1,182 @@
+package style
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	for _, name := range []string{Conventional, Gitmoji, Angular, Kernel, Plain} {
+		p, err := Get(name)
+		require.NoError(t, err)
+		assert.Equal(t, name, p.Name)
+	}
+
+	_, err := Get("unknown")
+	require.Error(t, err)
+}
+
+func TestPreset_Schema(t *testing.T) {
+	p, err := Get(Kernel)
+	require.NoError(t, err)
+
+	schema := p.Schema()
+
+	assert.Equal(t, []string{"body", "footers", "scope", "subject"}, schema["required"])
+	assert.Equal(t, false, schema["additionalProperties"])
+}
+
+func TestPreset_Format(t *testing.T) {
+	testCases := []struct {
+		name    string
+		preset  string
+		message Message
+		want    string
+	}{
+		{
+			name:    "conventional with scope and body",
+			preset:  Conventional,
+			message: Message{Type: "feat", Scope: "api", Subject: "add refund endpoint", Body: []string{"Adds POST /refunds.\n"}},
+			want:    "feat(api): add refund endpoint\n\nAdds POST /refunds.",
+		},
+		{
+			name:   "conventional breaking change with footers",
+			preset: Conventional,
+			message: Message{
+				Type:     "feat",
+				Scope:    "api",
+				Subject:  "drop v1 endpoints",
+				Body:     []string{"The v1 API was deprecated a year ago.", "", "Clients must use /v2."},
+				Breaking: "the /v1 endpoints are removed",
+				Footers:  []Footer{{Token: "Refs", Value: "#42"}, {Token: "Acked-by", Value: ""}},
+			},
+			want: "feat(api)!: drop v1 endpoints\n\nThe v1 API was deprecated a year ago.\n\nClients must use /v2.\n\n" +
+				"BREAKING CHANGE: the /v1 endpoints are removed\nRefs: #42",
+		},
+		{
+			name:    "angular without scope",
+			preset:  Angular,
+			message: Message{Type: "fix", Subject: "handle empty diff"},
+			want:    "fix: handle empty diff",
+		},
+		{
+			name:    "gitmoji",
+			preset:  Gitmoji,
+			message: Message{Type: ":sparkles:", Subject: "add refund endpoint"},
+			want:    ":sparkles: add refund endpoint",
+		},
+		{
+			name:    "kernel",
+			preset:  Kernel,
+			message: Message{Scope: "net/ipv4", Subject: "fix checksum offload", Body: []string{"Explain why."}},
+			want:    "net/ipv4: fix checksum offload\n\nExplain why.",
+		},
+		{
+			name:    "plain",
+			preset:  Plain,
+			message: Message{Subject: "Add refund endpoint"},
+			want:    "Add refund endpoint",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := Get(tc.preset)
+			require.NoError(t, err)
+
+			got := p.Format(&tc.message)
+
+			assert.Equal(t, tc.want, got)
+			assert.Empty(t, p.Validate(got))
+		})
+	}
+}
+
+func TestPreset_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		preset  string
+		message string
+	}{
+		{
+			name:    "conventional unknown type",
+			preset:  Conventional,
+			message: "feature: add refund endpoint",
+		},
+		{
+			name:    "conventional missing type",
+			preset:  Conventional,
+			message: "add refund endpoint",
+		},
+		{
+			name:    "angular chore type",
+			preset:  Angular,
+			message: "chore: bump deps",
+		},
+		{
+			name:    "angular capitalized subject",
+			preset:  Angular,
+			message: "feat: Add refund endpoint",
+		},
+		{
+			name:    "gitmoji missing emoji",
+			preset:  Gitmoji,
+			message: "add refund endpoint",
+		},
+		{
+			name:    "kernel missing subsystem",
+			preset:  Kernel,
+			message: "fix checksum offload",
+		},
+		{
+			name:    "plain trailing period",
+			preset:  Plain,
+			message: "Add refund endpoint.",
+		},
+		{
+			name:    "body not separated",
+			preset:  Plain,
+			message: "Add refund endpoint\nBody directly after subject",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := Get(tc.preset)
+			require.NoError(t, err)
+
+			assert.NotEmpty(t, p.Validate(tc.message))
+		})
+	}
+}
+
+func TestPreset_WithPrefix(t *testing.T) {
+	p, err := Get(Conventional)
+	require.NoError(t, err)
+
+	prefixed := p.WithPrefix("PAY-1234")
+
+	got := prefixed.Format(&Message{Type: "feat", Scope: "api", Subject: "add refund endpoint"})
+	assert.Equal(t, "PAY-1234 feat(api): add refund endpoint", got)
+	assert.Empty(t, prefixed.Validate(got))
+
+	assert.NotEmpty(t, prefixed.Validate("feat(api): add refund endpoint"))
+	assert.NotEmpty(t, prefixed.Validate("PAY-1234 feature: add refund endpoint"))
+	assert.Equal(t, "feat(api): add refund endpoint", p.Format(&Message{Type: "feat", Scope: "api", Subject: "add refund endpoint"}))
+}
+
+func TestInferred(t *testing.T) {
+	p := Inferred()
+
+	got := p.Format(&Message{
+		Header:  "[api] Add refund endpoint",
+		Body:    []string{"Adds POST /refunds."},
+		Footers: []Footer{{Token: "Refs", Value: "#42"}},
+	})
+
+	assert.Equal(t, "[api] Add refund endpoint\n\nAdds POST /refunds.\n\nRefs: #42", got)
+	assert.Empty(t, p.Validate(got))
+	assert.NotContains(t, Names(), p.Name)
+}
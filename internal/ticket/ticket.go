@@ -0,0 +1,119 @@
+package ticket
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	PlacementPrefix  = "prefix"
+	PlacementScope   = "scope"
+	PlacementTrailer = "trailer"
+)
+
+const refsTrailer = "Refs:"
+
+type Ticket struct {
+	ID        string
+	Placement string
+}
+
+// FromBranch extracts a ticket ID from the branch name using pattern. If the
+// pattern has a capture group, the first group is used as the ID, otherwise
+// the whole match. A nil ticket is returned when the branch has no ID.
+func FromBranch(pattern, branch, placement string) (*Ticket, error) {
+	rgx, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile ticket pattern: %w", err)
+	}
+
+	match := rgx.FindStringSubmatch(branch)
+	if match == nil {
+		return nil, nil
+	}
+
+	id := match[0]
+	if len(match) > 1 && match[1] != "" {
+		id = match[1]
+	}
+
+	return &Ticket{ID: id, Placement: placement}, nil
+}
+
+func (t *Ticket) Instruction() string {
+	switch t.Placement {
+	case PlacementPrefix:
+		return fmt.Sprintf("The commit message subject line must start with the ticket ID %s (e.g. '%s add refund flow')", t.ID, t.ID)
+	case PlacementScope:
+		return fmt.Sprintf("The commit message subject line must use the ticket ID %s as its scope (e.g. 'feat(%s): add refund flow')", t.ID, t.ID)
+	default:
+		return fmt.Sprintf("The commit message must end with a '%s %s' trailer line, separated from the rest of the message by a blank line", refsTrailer, t.ID)
+	}
+}
+
+// Validate reports the problems that prevent message from referencing the
+// ticket in the configured place.
+func (t *Ticket) Validate(message string) []string {
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+	subject := strings.TrimSpace(lines[0])
+
+	switch t.Placement {
+	case PlacementPrefix:
+		if !startsWithID(subject, t.ID) && !strings.HasPrefix(subject, "["+t.ID+"]") {
+			return []string{fmt.Sprintf("subject line must start with ticket ID %s", t.ID)}
+		}
+
+	case PlacementScope:
+		if !strings.Contains(subject, "("+t.ID+")") {
+			return []string{fmt.Sprintf("subject line must use ticket ID %s as scope, e.g. 'feat(%s): ...'", t.ID, t.ID)}
+		}
+
+	default:
+		for _, line := range trailerBlock(lines) {
+			value, ok := strings.CutPrefix(strings.TrimSpace(line), refsTrailer)
+			if !ok {
+				continue
+			}
+
+			ids := strings.FieldsFunc(value, func(r rune) bool {
+				return r == ',' || unicode.IsSpace(r)
+			})
+			if slices.Contains(ids, t.ID) {
+				return nil
+			}
+		}
+
+		return []string{fmt.Sprintf("message must end with a '%s %s' trailer", refsTrailer, t.ID)}
+	}
+
+	return nil
+}
+
+// trailerBlock returns the lines of the last paragraph, where git looks for
+// trailers. A message of a single paragraph has no trailers.
+func trailerBlock(lines []string) []string {
+	for i := len(lines) - 1; i > 0; i-- {
+		if strings.TrimSpace(lines[i]) == "" {
+			return lines[i+1:]
+		}
+	}
+
+	return nil
+}
+
+// startsWithID reports whether s starts with id as a whole word, so that
+// PAY-12345 doesn't count as PAY-1234.
+func startsWithID(s, id string) bool {
+	rest, ok := strings.CutPrefix(s, id)
+	if !ok {
+		return false
+	}
+
+	r, _ := utf8.DecodeRuneInString(rest)
+
+	return rest == "" || !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
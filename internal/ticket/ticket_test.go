@@ -0,0 +1,177 @@
+package ticket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromBranch(t *testing.T) {
+	testCases := []struct {
+		name    string
+		pattern string
+		branch  string
+		wantID  string
+		wantErr bool
+	}{
+		{
+			name:    "whole match",
+			pattern: `[A-Z]+-[0-9]+`,
+			branch:  "feature/PAY-1234-refund-flow",
+			wantID:  "PAY-1234",
+		},
+		{
+			name:    "capture group",
+			pattern: `^[a-z]+/([A-Z]+-[0-9]+)`,
+			branch:  "feature/PAY-1234-refund-flow",
+			wantID:  "PAY-1234",
+		},
+		{
+			name:    "no ticket in branch",
+			pattern: `[A-Z]+-[0-9]+`,
+			branch:  "main",
+		},
+		{
+			name:    "invalid pattern",
+			pattern: `[A-Z`,
+			branch:  "feature/PAY-1234",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := FromBranch(tc.pattern, tc.branch, PlacementTrailer)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			if tc.wantID == "" {
+				assert.Nil(t, got)
+				return
+			}
+
+			require.NotNil(t, got)
+			assert.Equal(t, tc.wantID, got.ID)
+		})
+	}
+}
+
+func TestTicket_Validate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		placement string
+		message   string
+		wantValid bool
+	}{
+		{
+			name:      "prefix present",
+			placement: PlacementPrefix,
+			message:   "PAY-1234 add refund flow",
+			wantValid: true,
+		},
+		{
+			name:      "bracketed prefix present",
+			placement: PlacementPrefix,
+			message:   "[PAY-1234] add refund flow",
+			wantValid: true,
+		},
+		{
+			name:      "prefix missing",
+			placement: PlacementPrefix,
+			message:   "add refund flow\n\nPAY-1234",
+			wantValid: false,
+		},
+		{
+			name:      "prefix followed by colon",
+			placement: PlacementPrefix,
+			message:   "PAY-1234: add refund flow",
+			wantValid: true,
+		},
+		{
+			name:      "prefix of a longer ID",
+			placement: PlacementPrefix,
+			message:   "PAY-12345 add refund flow",
+			wantValid: false,
+		},
+		{
+			name:      "scope present",
+			placement: PlacementScope,
+			message:   "feat(PAY-1234): add refund flow",
+			wantValid: true,
+		},
+		{
+			name:      "scope missing",
+			placement: PlacementScope,
+			message:   "feat(payments): add refund flow PAY-1234",
+			wantValid: false,
+		},
+		{
+			name:      "trailer present",
+			placement: PlacementTrailer,
+			message:   "feat: add refund flow\n\nSome body.\n\nRefs: PAY-1234",
+			wantValid: true,
+		},
+		{
+			name:      "trailer listing several IDs",
+			placement: PlacementTrailer,
+			message:   "feat: add refund flow\n\nRefs: PAY-99, PAY-1234",
+			wantValid: true,
+		},
+		{
+			name:      "trailer among other trailers",
+			placement: PlacementTrailer,
+			message:   "feat: add refund flow\n\nSome body.\n\nRefs: PAY-1234\nSigned-off-by: Alice <alice@example.com>",
+			wantValid: true,
+		},
+		{
+			name:      "trailer not in the last paragraph",
+			placement: PlacementTrailer,
+			message:   "feat: add refund flow\n\nRefs: PAY-1234\n\nMore body.",
+			wantValid: false,
+		},
+		{
+			name:      "trailer line without a blank line before",
+			placement: PlacementTrailer,
+			message:   "feat: add refund flow\nRefs: PAY-1234",
+			wantValid: false,
+		},
+		{
+			name:      "trailer with a longer ID",
+			placement: PlacementTrailer,
+			message:   "feat: add refund flow\n\nRefs: PAY-12345",
+			wantValid: false,
+		},
+		{
+			name:      "trailer with another ID",
+			placement: PlacementTrailer,
+			message:   "feat: add refund flow\n\nRefs: XPAY-1234",
+			wantValid: false,
+		},
+		{
+			name:      "trailer missing",
+			placement: PlacementTrailer,
+			message:   "feat: add refund flow PAY-1234",
+			wantValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ticket := &Ticket{ID: "PAY-1234", Placement: tc.placement}
+
+			violations := ticket.Validate(tc.message)
+
+			if tc.wantValid {
+				assert.Empty(t, violations)
+			} else {
+				assert.NotEmpty(t, violations)
+			}
+		})
+	}
+}
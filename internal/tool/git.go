@@ -39,7 +39,8 @@ func (t *Git) Params() map[string]any {
 				"description": "Git command arguments (e.g., ['status', '--porcelain'] or ['log', '--oneline', '-5'])",
 			},
 		},
-		"required": []string{"args"},
+		"required":             []string{"args"},
+		"additionalProperties": false,
 	}
 }
 
@@ -28,6 +28,8 @@ func (t *Glob) Params() map[string]any {
 				"description": "A glob pattern to match files, e.g., '*.txt' for text files or 'src/*_test.*' for test files.",
 			},
 		},
+		"required":             []string{"pattern"},
+		"additionalProperties": false,
 	}
 }
 
@@ -36,7 +36,8 @@ func (t *Grep) Params() map[string]any {
 				"description": "The path to the file or directory to search in. If it is a directory, the search will be recursive.",
 			},
 		},
-		"required": []string{"pattern", "path"},
+		"required":             []string{"pattern", "path"},
+		"additionalProperties": false,
 	}
 }
 
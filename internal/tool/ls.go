@@ -29,7 +29,8 @@ func (t *LS) Params() map[string]any {
 				"description": "The relative path of directory to list files. Default value is current directory if any path dont provided",
 			},
 		},
-		"required": []string{"path"},
+		"required":             []string{"path"},
+		"additionalProperties": false,
 	}
 }
 
@@ -28,7 +28,8 @@ func (t *Read) Params() map[string]any {
 				"description": "The relative path of a file in the working directory",
 			},
 		},
-		"required": []string{"path"},
+		"required":             []string{"path"},
+		"additionalProperties": false,
 	}
 }
 
@@ -0,0 +1,220 @@
+package tool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kinds of problems found by ValidateArgs.
+const (
+	ProblemInvalidJSON  = "invalid_json"
+	ProblemMissingField = "missing_field"
+	ProblemWrongType    = "wrong_type"
+	ProblemUnknownField = "unknown_field"
+)
+
+// ArgsError lists the problems found in the arguments of a tool call.
+type ArgsError struct {
+	Problems []Problem
+}
+
+// Problem is a single violation of the parameter schema. Field is the path of
+// the offending value, e.g. "args[1]", and empty for the whole input.
+type Problem struct {
+	Kind    string
+	Field   string
+	Message string
+}
+
+func (e *ArgsError) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		messages[i] = p.Message
+	}
+
+	return "invalid arguments: " + strings.Join(messages, "; ")
+}
+
+// ValidateArgs checks the JSON arguments of a tool call against the schema
+// returned by the tool's Params. It supports the subset of JSON schema used by
+// the tools: types, properties, required, additionalProperties and items.
+func ValidateArgs(schema map[string]any, input string) error {
+	dec := json.NewDecoder(strings.NewReader(input))
+	dec.UseNumber()
+
+	var value any
+	err := dec.Decode(&value)
+	if err == nil && dec.More() {
+		err = errors.New("unexpected data after the arguments")
+	}
+	if err != nil {
+		return &ArgsError{Problems: []Problem{{
+			Kind:    ProblemInvalidJSON,
+			Message: fmt.Sprintf("arguments are not valid JSON: %s", err),
+		}}}
+	}
+
+	var problems []Problem
+	validateValue(schema, value, "", &problems)
+
+	if len(problems) > 0 {
+		return &ArgsError{Problems: problems}
+	}
+
+	return nil
+}
+
+func validateValue(schema map[string]any, value any, field string, problems *[]Problem) {
+	want, _ := schema["type"].(string)
+	if want != "" && !hasType(value, want) {
+		*problems = append(*problems, Problem{
+			Kind:    ProblemWrongType,
+			Field:   field,
+			Message: fmt.Sprintf("%s must be %s, got %s", describeField(field), article(want), article(typeOf(value))),
+		})
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		validateObject(schema, v, field, problems)
+	case []any:
+		items, ok := schema["items"].(map[string]any)
+		if !ok {
+			return
+		}
+
+		for i, item := range v {
+			validateValue(items, item, fmt.Sprintf("%s[%d]", field, i), problems)
+		}
+	}
+}
+
+func validateObject(schema map[string]any, object map[string]any, field string, problems *[]Problem) {
+	properties, _ := schema["properties"].(map[string]any)
+
+	for _, name := range stringList(schema["required"]) {
+		if _, ok := object[name]; !ok {
+			*problems = append(*problems, Problem{
+				Kind:    ProblemMissingField,
+				Field:   join(field, name),
+				Message: fmt.Sprintf("missing required field %q", join(field, name)),
+			})
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, ok := properties[name].(map[string]any)
+		if !ok {
+			if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+				*problems = append(*problems, Problem{
+					Kind:    ProblemUnknownField,
+					Field:   join(field, name),
+					Message: fmt.Sprintf("unknown field %q, allowed fields are: %s", join(field, name), strings.Join(sortedKeys(properties), ", ")),
+				})
+			}
+			continue
+		}
+
+		validateValue(property, object[name], join(field, name), problems)
+	}
+}
+
+func hasType(value any, want string) bool {
+	switch want {
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	default:
+		return typeOf(value) == want
+	}
+}
+
+func typeOf(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func describeField(field string) string {
+	if field == "" {
+		return "arguments"
+	}
+
+	return fmt.Sprintf("field %q", field)
+}
+
+func article(typ string) string {
+	switch typ {
+	case "null":
+		return typ
+	case "array", "integer", "object":
+		return "an " + typ
+	default:
+		return "a " + typ
+	}
+}
+
+func join(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+
+	return parent + "." + name
+}
+
+// stringList accepts both []string, as written in Params, and []any, as
+// decoded from JSON.
+func stringList(value any) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	default:
+		return nil
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
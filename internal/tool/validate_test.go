@@ -0,0 +1,176 @@
+package tool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateArgs(t *testing.T) {
+	testCases := []struct {
+		name      string
+		tool      Tool
+		input     string
+		wantKinds []string
+		wantErr   string
+	}{
+		{
+			name:  "read_file valid",
+			tool:  &Read{},
+			input: `{"path":"main.go"}`,
+		},
+		{
+			name:      "read_file missing path",
+			tool:      &Read{},
+			input:     `{}`,
+			wantKinds: []string{ProblemMissingField},
+			wantErr:   `invalid arguments: missing required field "path"`,
+		},
+		{
+			name:      "read_file path not a string",
+			tool:      &Read{},
+			input:     `{"path":42}`,
+			wantKinds: []string{ProblemWrongType},
+			wantErr:   `invalid arguments: field "path" must be a string, got a number`,
+		},
+		{
+			name:      "read_file unknown field",
+			tool:      &Read{},
+			input:     `{"path":"main.go","lines":10}`,
+			wantKinds: []string{ProblemUnknownField},
+			wantErr:   `invalid arguments: unknown field "lines", allowed fields are: path`,
+		},
+		{
+			name:  "list_files valid",
+			tool:  &LS{},
+			input: `{"path":"."}`,
+		},
+		{
+			name:      "list_files misspelled field",
+			tool:      &LS{},
+			input:     `{"dir":"."}`,
+			wantKinds: []string{ProblemMissingField, ProblemUnknownField},
+			wantErr:   `invalid arguments: missing required field "path"; unknown field "dir", allowed fields are: path`,
+		},
+		{
+			name:  "git_command valid",
+			tool:  &Git{},
+			input: `{"args":["log","--oneline","-5"]}`,
+		},
+		{
+			name:      "git_command args not an array",
+			tool:      &Git{},
+			input:     `{"args":"status"}`,
+			wantKinds: []string{ProblemWrongType},
+			wantErr:   `invalid arguments: field "args" must be an array, got a string`,
+		},
+		{
+			name:      "git_command item not a string",
+			tool:      &Git{},
+			input:     `{"args":["log","-n",5]}`,
+			wantKinds: []string{ProblemWrongType},
+			wantErr:   `invalid arguments: field "args[2]" must be a string, got a number`,
+		},
+		{
+			name:      "git_command null args",
+			tool:      &Git{},
+			input:     `{"args":null}`,
+			wantKinds: []string{ProblemWrongType},
+			wantErr:   `invalid arguments: field "args" must be an array, got null`,
+		},
+		{
+			name:  "glob valid",
+			tool:  &Glob{},
+			input: `{"pattern":"*.go"}`,
+		},
+		{
+			name:      "glob missing pattern",
+			tool:      &Glob{},
+			input:     `{}`,
+			wantKinds: []string{ProblemMissingField},
+			wantErr:   `invalid arguments: missing required field "pattern"`,
+		},
+		{
+			name:      "glob unknown field",
+			tool:      &Glob{},
+			input:     `{"pattern":"*.go","path":"src"}`,
+			wantKinds: []string{ProblemUnknownField},
+			wantErr:   `invalid arguments: unknown field "path", allowed fields are: pattern`,
+		},
+		{
+			name:  "grep valid",
+			tool:  &Grep{},
+			input: `{"pattern":"func main","path":"."}`,
+		},
+		{
+			name:      "grep missing path and wrong pattern type",
+			tool:      &Grep{},
+			input:     `{"pattern":true}`,
+			wantKinds: []string{ProblemMissingField, ProblemWrongType},
+			wantErr:   `invalid arguments: missing required field "path"; field "pattern" must be a string, got a boolean`,
+		},
+		{
+			name:      "arguments not an object",
+			tool:      &Grep{},
+			input:     `["func main","."]`,
+			wantKinds: []string{ProblemWrongType},
+			wantErr:   `invalid arguments: arguments must be an object, got an array`,
+		},
+		{
+			name:      "invalid json",
+			tool:      &Read{},
+			input:     `{"path":`,
+			wantKinds: []string{ProblemInvalidJSON},
+			wantErr:   `invalid arguments: arguments are not valid JSON: unexpected EOF`,
+		},
+		{
+			name:      "trailing data",
+			tool:      &Read{},
+			input:     `{"path":"a"}{"path":"b"}`,
+			wantKinds: []string{ProblemInvalidJSON},
+			wantErr:   `invalid arguments: arguments are not valid JSON: unexpected data after the arguments`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateArgs(tc.tool.Params(), tc.input)
+
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			var argsErr *ArgsError
+			require.ErrorAs(t, err, &argsErr)
+			assert.EqualError(t, err, tc.wantErr)
+
+			kinds := make([]string, len(argsErr.Problems))
+			for i, p := range argsErr.Problems {
+				kinds[i] = p.Kind
+			}
+			assert.Equal(t, tc.wantKinds, kinds)
+		})
+	}
+}
+
+func TestParams(t *testing.T) {
+	for _, tool := range []Tool{&Read{}, &LS{}, &Git{}, &Glob{}, &Grep{}} {
+		t.Run(tool.Name(), func(t *testing.T) {
+			params := tool.Params()
+
+			assert.Equal(t, "object", params["type"])
+			assert.Equal(t, false, params["additionalProperties"])
+
+			properties, ok := params["properties"].(map[string]any)
+			require.True(t, ok)
+
+			required, ok := params["required"].([]string)
+			require.True(t, ok, "required fields must be declared")
+			for _, name := range required {
+				assert.Contains(t, properties, name)
+			}
+		})
+	}
+}
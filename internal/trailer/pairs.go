@@ -0,0 +1,74 @@
+package trailer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadPairs reads a pairing file mapping aliases to co-author identities.
+// Each line has the form "alias: Full Name <email>"; empty lines and lines
+// starting with '#' are ignored. A missing file yields an empty mapping.
+func LoadPairs(path string) (map[string]string, error) {
+	pairs := make(map[string]string)
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return pairs, nil
+		}
+
+		return nil, fmt.Errorf("failed to open pairs file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		alias, identity, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(alias) == "" || strings.TrimSpace(identity) == "" {
+			return nil, fmt.Errorf("%s:%d: expected 'alias: Full Name <email>'", path, n)
+		}
+
+		pairs[strings.TrimSpace(alias)] = strings.TrimSpace(identity)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pairs file: %w", err)
+	}
+
+	return pairs, nil
+}
+
+// CoAuthors resolves pair aliases into Co-authored-by trailers. Values that
+// already look like an identity ("Name <email>") are used as is.
+func CoAuthors(pairs map[string]string, aliases []string) ([]Trailer, error) {
+	var trailers []Trailer
+
+	for _, alias := range aliases {
+		alias = strings.TrimSpace(alias)
+		if alias == "" {
+			continue
+		}
+
+		identity, ok := pairs[alias]
+		if !ok {
+			if !strings.Contains(alias, "<") {
+				return nil, fmt.Errorf("unknown pair %q", alias)
+			}
+			identity = alias
+		}
+
+		trailers = append(trailers, Trailer{Key: KeyCoAuthoredBy, Value: identity})
+	}
+
+	return trailers, nil
+}
@@ -0,0 +1,63 @@
+package trailer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPairs(t *testing.T) {
+	tempDir := t.TempDir()
+
+	t.Run("valid file", func(t *testing.T) {
+		path := filepath.Join(tempDir, "pairs")
+		content := "# team\nalice: Alice Doe <alice@example.com>\n\nbob: Bob Roe <bob@example.com>\n"
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+		pairs, err := LoadPairs(path)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]string{
+			"alice": "Alice Doe <alice@example.com>",
+			"bob":   "Bob Roe <bob@example.com>",
+		}, pairs)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		pairs, err := LoadPairs(filepath.Join(tempDir, "nonexistent"))
+		require.NoError(t, err)
+		assert.Empty(t, pairs)
+	})
+
+	t.Run("malformed line", func(t *testing.T) {
+		path := filepath.Join(tempDir, "broken")
+		require.NoError(t, os.WriteFile(path, []byte("alice Alice Doe\n"), 0644))
+
+		_, err := LoadPairs(path)
+		require.Error(t, err)
+	})
+}
+
+func TestCoAuthors(t *testing.T) {
+	pairs := map[string]string{
+		"alice": "Alice Doe <alice@example.com>",
+	}
+
+	t.Run("known alias and raw identity", func(t *testing.T) {
+		got, err := CoAuthors(pairs, []string{"alice", " Carol <carol@example.com>", ""})
+		require.NoError(t, err)
+
+		assert.Equal(t, []Trailer{
+			{Key: KeyCoAuthoredBy, Value: "Alice Doe <alice@example.com>"},
+			{Key: KeyCoAuthoredBy, Value: "Carol <carol@example.com>"},
+		}, got)
+	})
+
+	t.Run("unknown alias", func(t *testing.T) {
+		_, err := CoAuthors(pairs, []string{"bob"})
+		require.Error(t, err)
+	})
+}
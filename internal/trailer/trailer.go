@@ -0,0 +1,77 @@
+package trailer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/haadi-coder/Git-Agent/internal/git"
+)
+
+const (
+	KeySignedOffBy  = "Signed-off-by"
+	KeyCoAuthoredBy = "Co-authored-by"
+)
+
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+func (t Trailer) String() string {
+	return t.Key + ": " + t.Value
+}
+
+// Parse parses a trailer written as "Key: value" or "Key=value".
+func Parse(s string) (Trailer, error) {
+	sep := strings.IndexAny(s, ":=")
+	if sep <= 0 {
+		return Trailer{}, fmt.Errorf("invalid trailer %q, expected 'Key: value'", s)
+	}
+
+	key := strings.TrimSpace(s[:sep])
+	value := strings.TrimSpace(s[sep+1:])
+
+	if key == "" || value == "" || strings.ContainsAny(key, " \t") {
+		return Trailer{}, fmt.Errorf("invalid trailer %q, expected 'Key: value'", s)
+	}
+
+	return Trailer{Key: key, Value: value}, nil
+}
+
+// SignOff builds a DCO Signed-off-by trailer from the user.name and
+// user.email git settings.
+func SignOff(ctx context.Context) (Trailer, error) {
+	name, err := git.Config(ctx, "user.name")
+	if err != nil {
+		return Trailer{}, err
+	}
+
+	email, err := git.Config(ctx, "user.email")
+	if err != nil {
+		return Trailer{}, err
+	}
+
+	return Trailer{Key: KeySignedOffBy, Value: fmt.Sprintf("%s <%s>", name, email)}, nil
+}
+
+// Apply appends trailers to the trailer block at the end of message using
+// git interpret-trailers. Trailers identical to ones already present in the
+// message are not added again.
+func Apply(ctx context.Context, message string, trailers []Trailer) (string, error) {
+	if len(trailers) == 0 {
+		return message, nil
+	}
+
+	args := []string{"interpret-trailers", "--if-exists", "addIfDifferent"}
+	for _, t := range trailers {
+		args = append(args, "--trailer", t.String())
+	}
+
+	out, err := git.RunInput(ctx, strings.NewReader(message+"\n"), args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to apply trailers: %w", err)
+	}
+
+	return strings.TrimRight(out, "\n"), nil
+}
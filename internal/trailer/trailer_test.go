@@ -0,0 +1,106 @@
+package trailer
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		want    Trailer
+		wantErr bool
+	}{
+		{
+			name:  "colon separated",
+			input: "Reviewed-by: Alice <alice@example.com>",
+			want:  Trailer{Key: "Reviewed-by", Value: "Alice <alice@example.com>"},
+		},
+		{
+			name:  "equals separated",
+			input: "Refs=PAY-1234",
+			want:  Trailer{Key: "Refs", Value: "PAY-1234"},
+		},
+		{
+			name:    "missing separator",
+			input:   "Refs PAY-1234",
+			wantErr: true,
+		},
+		{
+			name:    "empty value",
+			input:   "Refs:",
+			wantErr: true,
+		},
+		{
+			name:    "key with spaces",
+			input:   "Some key: value",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Parse(tc.input)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git command not available")
+	}
+
+	ctx := context.Background()
+
+	testCases := []struct {
+		name     string
+		message  string
+		trailers []Trailer
+		want     string
+	}{
+		{
+			name:    "no trailers",
+			message: "feat: add refund flow",
+			want:    "feat: add refund flow",
+		},
+		{
+			name:    "new trailer block",
+			message: "feat: add refund flow\n\nAdds the refund endpoint.",
+			trailers: []Trailer{
+				{Key: KeySignedOffBy, Value: "Alice <alice@example.com>"},
+			},
+			want: "feat: add refund flow\n\nAdds the refund endpoint.\n\nSigned-off-by: Alice <alice@example.com>",
+		},
+		{
+			name:    "existing trailer is not duplicated",
+			message: "feat: add refund flow\n\nRefs: PAY-1234",
+			trailers: []Trailer{
+				{Key: "Refs", Value: "PAY-1234"},
+				{Key: KeyCoAuthoredBy, Value: "Bob <bob@example.com>"},
+			},
+			want: "feat: add refund flow\n\nRefs: PAY-1234\nCo-authored-by: Bob <bob@example.com>",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Apply(ctx, tc.message, tc.trailers)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,370 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/haadi-coder/Git-Agent/internal/agent"
+	"github.com/rivo/tview"
+)
+
+const (
+	keyHelp       = "[yellow]Ctrl-S[-] commit  [yellow]Ctrl-R[-] regenerate  [yellow]Tab[-] switch pane  [yellow]Esc[-] abort"
+	candidateHelp = "  [yellow]Ctrl-N[-] next candidate"
+)
+
+// Handlers connect the UI to the agent and to git.
+type Handlers struct {
+	// Generate produces a response. An empty feedback starts a new session,
+	// otherwise the previous one is continued with the feedback.
+	Generate func(ctx context.Context, feedback string) (*agent.Response, error)
+	Commit   func(ctx context.Context, message string) error
+}
+
+// UI is a full-screen terminal interface showing the staged diff, the agent
+// activity and the editable commit message side by side.
+type UI struct {
+	app      *tview.Application
+	pages    *tview.Pages
+	diff     *tview.TextView
+	activity *tview.TextView
+	message  *tview.TextArea
+	status   *tview.TextView
+	feedback *tview.InputField
+
+	// candidates holds the alternatives of the last response if there are
+	// several, candidate is the one in the message pane. Both are only used
+	// from the UI event loop.
+	candidates []agent.Candidate
+	candidate  int
+
+	mu        sync.Mutex
+	busy      bool
+	committed bool
+}
+
+func New() *UI {
+	u := &UI{
+		app:      tview.NewApplication(),
+		pages:    tview.NewPages(),
+		diff:     tview.NewTextView(),
+		activity: tview.NewTextView(),
+		message:  tview.NewTextArea(),
+		status:   tview.NewTextView(),
+		feedback: tview.NewInputField(),
+	}
+
+	u.diff.SetDynamicColors(true).SetScrollable(true).SetWrap(false)
+	u.diff.SetBorder(true).SetTitle(" Staged diff ")
+
+	u.activity.SetDynamicColors(true).SetScrollable(true).SetWordWrap(true)
+	u.activity.SetChangedFunc(func() { u.app.Draw() })
+	u.activity.SetBorder(true).SetTitle(" Agent ")
+
+	u.message.SetPlaceholder("Waiting for the agent...")
+	u.message.SetBorder(true).SetTitle(" Commit message ")
+
+	u.status.SetDynamicColors(true).SetText(keyHelp)
+
+	u.feedback.SetLabel("What should be changed? ")
+	u.feedback.SetBorder(true).SetTitle(" Regenerate ")
+
+	right := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(u.activity, 0, 1, false).
+		AddItem(u.message, 0, 1, false)
+
+	body := tview.NewFlex().
+		AddItem(u.diff, 0, 1, false).
+		AddItem(right, 0, 1, false)
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(body, 0, 1, false).
+		AddItem(u.status, 1, 0, false)
+
+	modal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(u.feedback, 3, 0, true).
+			AddItem(nil, 0, 1, false), 0, 2, true).
+		AddItem(nil, 0, 1, false)
+
+	u.pages.AddPage("main", layout, true, true)
+	u.pages.AddPage("feedback", modal, true, false)
+
+	return u
+}
+
+// Logf appends a line to the agent activity pane. It is safe to call from
+// any goroutine, including the UI event loop.
+func (u *UI) Logf(format string, args ...any) {
+	_, _ = fmt.Fprintf(u.activity, format+"\n", args...)
+}
+
+// Append adds text to the agent activity pane without starting a new line.
+func (u *UI) Append(text string) {
+	_, _ = fmt.Fprint(u.activity, text)
+}
+
+// AppendMessage adds text to the commit message pane. It must not be called
+// from the UI event loop.
+func (u *UI) AppendMessage(text string) {
+	u.app.QueueUpdateDraw(func() {
+		u.message.SetText(u.message.GetText()+text, true)
+	})
+}
+
+// ClearMessage empties the commit message pane. It must not be called from
+// the UI event loop.
+func (u *UI) ClearMessage() {
+	u.app.QueueUpdateDraw(func() {
+		u.message.SetText("", false)
+	})
+}
+
+// Run shows the UI until the message is committed or the user aborts. It
+// reports whether a commit was created.
+func (u *UI) Run(ctx context.Context, diff string, h Handlers) (bool, error) {
+	u.diff.SetText(colorDiff(diff))
+
+	u.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if name, _ := u.pages.GetFrontPage(); name == "feedback" {
+			return event
+		}
+
+		switch event.Key() {
+		case tcell.KeyCtrlS:
+			u.commit(ctx, h)
+			return nil
+		case tcell.KeyCtrlR:
+			u.askFeedback()
+			return nil
+		case tcell.KeyCtrlN:
+			u.nextCandidate()
+			return nil
+		case tcell.KeyTab:
+			u.cycleFocus()
+			return nil
+		case tcell.KeyEscape, tcell.KeyCtrlC:
+			u.app.Stop()
+			return nil
+		}
+
+		return event
+	})
+
+	u.feedback.SetDoneFunc(func(key tcell.Key) {
+		text := strings.TrimSpace(u.feedback.GetText())
+		u.feedback.SetText("")
+		u.pages.HidePage("feedback")
+		u.app.SetFocus(u.message)
+
+		if key == tcell.KeyEnter && text != "" {
+			u.generate(ctx, h, text)
+		}
+	})
+
+	go func() {
+		<-ctx.Done()
+		u.app.Stop()
+	}()
+
+	u.generate(ctx, h, "")
+
+	if err := u.app.SetRoot(u.pages, true).SetFocus(u.message).Run(); err != nil {
+		return false, fmt.Errorf("failed to run terminal UI: %w", err)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.committed, nil
+}
+
+func (u *UI) generate(ctx context.Context, h Handlers, feedback string) {
+	if !u.acquire() {
+		return
+	}
+
+	u.message.SetText("", false)
+	u.showCandidates(nil)
+
+	if feedback == "" {
+		u.Logf("[cyan]🔎 Analyzing changes...[-]")
+	} else {
+		u.Logf("[cyan]🔄 Regenerating: %s[-]", tview.Escape(feedback))
+	}
+
+	go func() {
+		defer u.release()
+
+		resp, err := h.Generate(ctx, feedback)
+		if err != nil {
+			u.Logf("[red]Error: %s[-]", tview.Escape(err.Error()))
+			return
+		}
+
+		switch resp.Type {
+		case agent.ResponseTypeError:
+			u.Logf("[red]llm error: %s[-]", tview.Escape(resp.Value))
+		case agent.ResponseTypeBudgetExhausted:
+			u.Logf("[red]Budget exhausted: %s[-]", tview.Escape(resp.Value))
+		case agent.ResponseTypeSuggestion:
+			u.Logf("[cyan]Suggestion:[-] %s", tview.Escape(resp.Value))
+		case agent.ResponseTypeResult:
+			u.app.QueueUpdateDraw(func() {
+				u.message.SetText(resp.Value, false)
+				u.showCandidates(resp.Candidates)
+			})
+
+			if len(resp.Candidates) < 2 {
+				u.Logf("[green]📜 Message ready, edit it or press Ctrl-S to commit[-]")
+				return
+			}
+
+			for i, c := range resp.Candidates {
+				subject, _, _ := strings.Cut(c.Value, "\n")
+				u.Logf("[cyan]%d)[-] %s [gray]%s[-]", i+1, tview.Escape(subject), tview.Escape(c.Rationale))
+			}
+			u.Logf("[green]📜 %d messages ready, Ctrl-N shows the next one, edit it or press Ctrl-S to commit[-]", len(resp.Candidates))
+		}
+	}()
+}
+
+func (u *UI) commit(ctx context.Context, h Handlers) {
+	message := strings.TrimSpace(u.message.GetText())
+	if message == "" {
+		u.Logf("[red]Commit message is empty[-]")
+		return
+	}
+
+	if !u.acquire() {
+		return
+	}
+
+	go func() {
+		defer u.release()
+
+		if err := h.Commit(ctx, message); err != nil {
+			u.Logf("[red]Failed to commit: %s[-]", tview.Escape(err.Error()))
+			return
+		}
+
+		u.mu.Lock()
+		u.committed = true
+		u.mu.Unlock()
+
+		u.app.Stop()
+	}()
+}
+
+func (u *UI) askFeedback() {
+	u.mu.Lock()
+	busy := u.busy
+	u.mu.Unlock()
+
+	if busy {
+		return
+	}
+
+	u.pages.ShowPage("feedback")
+	u.app.SetFocus(u.feedback)
+}
+
+// showCandidates keeps the alternatives of a response, the first one being
+// in the message pane, and shows the position in the pane title.
+func (u *UI) showCandidates(candidates []agent.Candidate) {
+	if len(candidates) < 2 {
+		candidates = nil
+	}
+
+	u.candidates = candidates
+	u.candidate = 0
+	u.updateCandidateInfo()
+}
+
+// nextCandidate replaces the message pane with the next alternative. Edits
+// of the current one are discarded.
+func (u *UI) nextCandidate() {
+	if len(u.candidates) == 0 {
+		return
+	}
+
+	u.candidate = (u.candidate + 1) % len(u.candidates)
+	u.message.SetText(u.candidates[u.candidate].Value, false)
+	u.updateCandidateInfo()
+}
+
+func (u *UI) updateCandidateInfo() {
+	if len(u.candidates) == 0 {
+		u.message.SetTitle(" Commit message ")
+		u.status.SetText(keyHelp)
+		return
+	}
+
+	u.message.SetTitle(fmt.Sprintf(" Commit message %d/%d ", u.candidate+1, len(u.candidates)))
+	u.status.SetText(keyHelp + candidateHelp)
+}
+
+func (u *UI) cycleFocus() {
+	switch u.app.GetFocus() {
+	case u.diff:
+		u.app.SetFocus(u.activity)
+	case u.activity:
+		u.app.SetFocus(u.message)
+	default:
+		u.app.SetFocus(u.diff)
+	}
+}
+
+// acquire marks the UI busy. It returns false when another operation is
+// already running.
+func (u *UI) acquire() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.busy {
+		return false
+	}
+	u.busy = true
+
+	return true
+}
+
+func (u *UI) release() {
+	u.mu.Lock()
+	u.busy = false
+	u.mu.Unlock()
+}
+
+// colorDiff highlights a unified diff using tview color tags.
+func colorDiff(diff string) string {
+	if strings.TrimSpace(diff) == "" {
+		return "[gray]No staged changes[-]"
+	}
+
+	var sb strings.Builder
+
+	for _, line := range strings.Split(diff, "\n") {
+		escaped := tview.Escape(line)
+
+		switch {
+		case strings.HasPrefix(line, "diff --git"), strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			sb.WriteString("[yellow::b]" + escaped + "[-::-]")
+		case strings.HasPrefix(line, "@@"):
+			sb.WriteString("[cyan]" + escaped + "[-]")
+		case strings.HasPrefix(line, "+"):
+			sb.WriteString("[green]" + escaped + "[-]")
+		case strings.HasPrefix(line, "-"):
+			sb.WriteString("[red]" + escaped + "[-]")
+		default:
+			sb.WriteString(escaped)
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/haadi-coder/Git-Agent/internal/agent"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorDiff(t *testing.T) {
+	testCases := []struct {
+		name string
+		diff string
+		want string
+	}{
+		{
+			name: "empty diff",
+			diff: "\n",
+			want: "[gray]No staged changes[-]",
+		},
+		{
+			name: "hunk",
+			diff: "diff --git a/x b/x\n@@ -1 +1 @@\n-old\n+new [tag]\n ctx",
+			want: "[yellow::b]diff --git a/x b/x[-::-]\n" +
+				"[cyan]@@ -1 +1 @@[-]\n" +
+				"[red]-old[-]\n" +
+				"[green]+new [tag[][-]\n" +
+				" ctx\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, colorDiff(tc.diff))
+		})
+	}
+}
+
+func TestUI_NextCandidate(t *testing.T) {
+	u := New()
+
+	u.message.SetText("first", false)
+	u.showCandidates([]agent.Candidate{{Value: "first"}, {Value: "second"}, {Value: "third"}})
+	assert.Equal(t, " Commit message 1/3 ", u.message.GetTitle())
+
+	u.nextCandidate()
+	assert.Equal(t, "second", u.message.GetText())
+	assert.Equal(t, " Commit message 2/3 ", u.message.GetTitle())
+
+	u.nextCandidate()
+	u.nextCandidate()
+	assert.Equal(t, "first", u.message.GetText())
+
+	u.showCandidates([]agent.Candidate{{Value: "only"}})
+	u.nextCandidate()
+	assert.Equal(t, "first", u.message.GetText())
+	assert.Equal(t, " Commit message ", u.message.GetTitle())
+}